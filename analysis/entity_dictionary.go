@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 词典只收录至少包含该字符数的名称，避免单字实体在自由文本中误匹配
+const minDictionaryTermLength = 2
+
+// EntityDictionary 由实体名称构建的字典树，用最长匹配从自由文本中识别实体
+type EntityDictionary struct {
+	root  *trieNode
+	terms int
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// entities 以该节点结尾的名称对应的实体，为空表示不是完整名称
+	entities []model.EntityNode
+}
+
+// NewEntityDictionary 以归一化的名称和别名构建词典，同名实体挂在同一词条下
+func NewEntityDictionary(entities []model.EntityNode) *EntityDictionary {
+	d := &EntityDictionary{root: &trieNode{}}
+	for _, e := range entities {
+		d.insert(e.Name, e)
+		for _, alias := range e.Aliases {
+			d.insert(alias, e)
+		}
+	}
+	return d
+}
+
+func (d *EntityDictionary) insert(term string, e model.EntityNode) {
+	term = model.NormalizeEntityName(term)
+	if utf8.RuneCountInString(term) < minDictionaryTermLength {
+		return
+	}
+
+	node := d.root
+	for _, r := range term {
+		if node.children == nil {
+			node.children = make(map[rune]*trieNode)
+		}
+		child, ok := node.children[r]
+		if !ok {
+			child = &trieNode{}
+			node.children[r] = child
+		}
+		node = child
+	}
+	for _, existing := range node.entities {
+		if existing.ID == e.ID && existing.Name == e.Name && existing.Type == e.Type {
+			return
+		}
+	}
+	if len(node.entities) == 0 {
+		d.terms++
+	}
+	node.entities = append(node.entities, e)
+}
+
+// Len 词典中的词条数
+func (d *EntityDictionary) Len() int {
+	return d.terms
+}
+
+// Extract 从左到右最长匹配文本中的实体名称，匹配不区分全半角和大小写，连续空白视为一个空格；
+// 英文和数字组成的名称要求前后不紧邻英文或数字，避免匹配到单词内部
+func (d *EntityDictionary) Extract(text string) []model.QueryTerm {
+	original := []rune(text)
+
+	// folded 为归一化后的文本，offsets[i] 为 folded[i] 在原文中的字符偏移
+	var folded []rune
+	var offsets []int
+	for i, r := range original {
+		r = model.FoldEntityRune(r)
+		if unicode.IsSpace(r) {
+			if len(folded) > 0 && folded[len(folded)-1] == ' ' {
+				continue
+			}
+			r = ' '
+		}
+		folded = append(folded, r)
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(original))
+
+	var terms []model.QueryTerm
+	for i := 0; i < len(folded); {
+		end, entities := d.longestMatch(folded, i)
+		if end < 0 {
+			i++
+			continue
+		}
+
+		// 原文中的结束位置为最后一个匹配字符之后
+		start, stop := offsets[i], offsets[end-1]+1
+		terms = append(terms, model.QueryTerm{
+			Text:     string(original[start:stop]),
+			Start:    start,
+			End:      stop,
+			Entities: entities,
+		})
+		i = end
+	}
+	return terms
+}
+
+// longestMatch 返回从 start 开始的最长词条的结束位置，不存在时返回 -1
+func (d *EntityDictionary) longestMatch(text []rune, start int) (int, []model.EntityNode) {
+	if start > 0 && isWordRune(text[start-1]) && isWordRune(text[start]) {
+		return -1, nil
+	}
+
+	end := -1
+	var entities []model.EntityNode
+	node := d.root
+	for i := start; i < len(text); i++ {
+		node = node.children[text[i]]
+		if node == nil {
+			break
+		}
+		if len(node.entities) == 0 {
+			continue
+		}
+		if i+1 < len(text) && isWordRune(text[i]) && isWordRune(text[i+1]) {
+			continue
+		}
+		end, entities = i+1, node.entities
+	}
+	return end, entities
+}
+
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,103 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEntityDictionaryExtract(t *testing.T) {
+	dict := NewEntityDictionary([]model.EntityNode{
+		{ID: "1", Name: "糖尿病", Type: "Disease"},
+		{ID: "2", Name: "2型糖尿病", Type: "Disease", Aliases: []string{"T2DM", "二型糖尿病"}},
+		{ID: "3", Name: "糖尿病肾病", Type: "Disease"},
+		{ID: "4", Name: "二甲双胍", Type: "Drug", Aliases: []string{"Metformin"}},
+		{ID: "5", Name: "肾病", Type: "Disease"},
+		{ID: "6", Name: "低血糖", Type: "Symptom"},
+		{ID: "7", Name: "低血糖", Type: "ADE"},
+		// 单字名称不收录
+		{ID: "8", Name: "糖", Type: "Food"},
+		{ID: "9", Name: "insulin glargine", Type: "Drug"},
+	})
+
+	if got := dict.Len(); got != 10 {
+		t.Errorf("Len() = %d, want 10", got)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		// want 识别出的片段，格式为 原文[起止字符偏移]实体 ID
+		want []string
+	}{
+		{
+			name: "longest match wins over prefix",
+			text: "糖尿病肾病怎么治疗",
+			want: []string{"糖尿病肾病[0,5]3"},
+		},
+		{
+			name: "overlapping names consumed left to right",
+			text: "2型糖尿病肾病",
+			want: []string{"2型糖尿病[0,5]2", "肾病[5,7]5"},
+		},
+		{
+			name: "cjk text without whitespace",
+			text: "服用二甲双胍后出现低血糖和糖尿病",
+			want: []string{"二甲双胍[2,6]4", "低血糖[9,12]6,7", "糖尿病[13,16]1"},
+		},
+		{
+			name: "alias maps to entity",
+			text: "二型糖尿病能用metformin吗",
+			want: []string{"二型糖尿病[0,5]2", "metformin[7,16]4"},
+		},
+		{
+			name: "full width and case folded",
+			text: "ＭＥＴＦＯＲＭＩＮ的剂量",
+			want: []string{"ＭＥＴＦＯＲＭＩＮ[0,9]4"},
+		},
+		{
+			name: "whitespace runs match a single space",
+			text: "insulin \t glargine",
+			want: []string{"insulin \t glargine[0,18]9"},
+		},
+		{
+			name: "no match inside english words",
+			text: "T2DMX and xT2DM and T2DM",
+			want: []string{"T2DM[20,24]2"},
+		},
+		{
+			name: "single character names ignored",
+			text: "糖",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, term := range dict.Extract(tt.text) {
+				var ids []string
+				for _, e := range term.Entities {
+					ids = append(ids, e.ID)
+				}
+				got = append(got, fmt.Sprintf("%s[%d,%d]%s", term.Text, term.Start, term.End, strings.Join(ids, ",")))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Extract(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntityDictionaryDuplicates(t *testing.T) {
+	e := model.EntityNode{ID: "1", Name: "二甲双胍", Type: "Drug", Aliases: []string{"二甲双胍"}}
+	dict := NewEntityDictionary([]model.EntityNode{e, e})
+	if got := dict.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+	terms := dict.Extract("二甲双胍")
+	if len(terms) != 1 || len(terms[0].Entities) != 1 {
+		t.Errorf("Extract() = %+v, want one term with one entity", terms)
+	}
+}
@@ -0,0 +1,167 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ExerciseImpact 某一运动类型与强度组合对血糖的影响汇总
+type ExerciseImpact struct {
+	Type           string   `json:"type"`
+	Intensity      string   `json:"intensity"`
+	Names          []string `json:"names"`
+	Sessions       int      `json:"sessions"`
+	AvgDuration    float64  `json:"avg_duration"`
+	AvgPreGlucose  *float64 `json:"avg_pre_glucose,omitempty"`
+	AvgPostGlucose *float64 `json:"avg_post_glucose,omitempty"`
+	// AvgDelta 同时记录运动前后血糖的场次的平均变化（后 - 前）
+	AvgDelta      *float64 `json:"avg_delta,omitempty"`
+	DeltaSessions int      `json:"delta_sessions"`
+	// 运动结束后窗口内的血糖读数统计
+	PostWindowReadings int      `json:"post_window_readings"`
+	PostWindowMean     *float64 `json:"post_window_mean,omitempty"`
+	PostWindowMin      *float64 `json:"post_window_min,omitempty"`
+	// DelayedHypoSessions 窗口内出现低于阈值读数的场次
+	DelayedHypoSessions int     `json:"delayed_hypo_sessions"`
+	DelayedHypoRate     float64 `json:"delayed_hypo_rate"`
+	// InferredUnitRecords 存储时未标明单位、按数值推断单位换算的运动记录和窗口内读数数量
+	InferredUnitRecords int `json:"inferred_unit_records,omitempty"`
+}
+
+type ExerciseImpactReport struct {
+	Unit            string           `json:"unit"`
+	Start           time.Time        `json:"start"`
+	End             time.Time        `json:"end"`
+	PostWindowHours float64          `json:"post_window_hours"`
+	Activities      []ExerciseImpact `json:"activities"`
+}
+
+type ExerciseImpactOptions struct {
+	// PostWindow 运动结束后纳入统计的时长
+	PostWindow time.Duration
+	// HypoThreshold 判定迟发性低血糖的阈值，与读数使用同一单位
+	HypoThreshold float64
+}
+
+type exerciseKey struct {
+	exerciseType string
+	intensity    string
+}
+
+type exerciseAccumulator struct {
+	impact         ExerciseImpact
+	names          map[string]bool
+	duration       float64
+	pre, post      []float64
+	deltas         []float64
+	windowReadings []float64
+}
+
+// AnalyzeExerciseImpact 按运动类型和强度汇总运动对血糖的影响，按平均降糖幅度从大到小排序
+func AnalyzeExerciseImpact(exercises []model.ExerciseRecord, records []model.BloodGlucoseRecord, opts ExerciseImpactOptions) []ExerciseImpact {
+	records = sortByMeasuredAt(records)
+
+	groups := make(map[exerciseKey]*exerciseAccumulator)
+	var keys []exerciseKey
+	for _, e := range exercises {
+		key := exerciseKey{exerciseType: e.Type, intensity: e.Intensity}
+		acc, ok := groups[key]
+		if !ok {
+			acc = &exerciseAccumulator{
+				impact: ExerciseImpact{Type: e.Type, Intensity: e.Intensity},
+				names:  make(map[string]bool),
+			}
+			groups[key] = acc
+			keys = append(keys, key)
+		}
+		acc.add(e, records, opts)
+	}
+
+	impacts := make([]ExerciseImpact, 0, len(keys))
+	for _, key := range keys {
+		impacts = append(impacts, groups[key].result())
+	}
+
+	// 有前后血糖差值的组排在前面，差值越小（降糖越多）越靠前
+	sort.SliceStable(impacts, func(i, j int) bool {
+		a, b := impacts[i].AvgDelta, impacts[j].AvgDelta
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+
+	return impacts
+}
+
+func (acc *exerciseAccumulator) add(e model.ExerciseRecord, records []model.BloodGlucoseRecord, opts ExerciseImpactOptions) {
+	acc.impact.Sessions++
+	acc.duration += float64(e.Duration)
+	if !acc.names[e.Name] {
+		acc.names[e.Name] = true
+		acc.impact.Names = append(acc.impact.Names, e.Name)
+	}
+
+	if e.UnitInferred {
+		acc.impact.InferredUnitRecords++
+	}
+	if e.PreGlucose > 0 {
+		acc.pre = append(acc.pre, float64(e.PreGlucose))
+	}
+	if e.PostGlucose > 0 {
+		acc.post = append(acc.post, float64(e.PostGlucose))
+	}
+	if e.PreGlucose > 0 && e.PostGlucose > 0 {
+		acc.deltas = append(acc.deltas, float64(e.PostGlucose-e.PreGlucose))
+	}
+
+	windowEnd := e.EndAt.Add(opts.PostWindow)
+	hypo := false
+	for _, r := range records {
+		if r.MeasuredAt.Before(e.EndAt) {
+			continue
+		}
+		if r.MeasuredAt.After(windowEnd) {
+			break
+		}
+		v := float64(r.Value)
+		acc.windowReadings = append(acc.windowReadings, v)
+		if r.UnitInferred {
+			acc.impact.InferredUnitRecords++
+		}
+		if v < opts.HypoThreshold {
+			hypo = true
+		}
+	}
+	if hypo {
+		acc.impact.DelayedHypoSessions++
+	}
+}
+
+func (acc *exerciseAccumulator) result() ExerciseImpact {
+	impact := acc.impact
+	impact.AvgDuration = round(acc.duration / float64(impact.Sessions))
+	impact.AvgPreGlucose = meanPtr(acc.pre)
+	impact.AvgPostGlucose = meanPtr(acc.post)
+	impact.AvgDelta = meanPtr(acc.deltas)
+	impact.DeltaSessions = len(acc.deltas)
+	impact.PostWindowReadings = len(acc.windowReadings)
+	impact.PostWindowMean = meanPtr(acc.windowReadings)
+	if len(acc.windowReadings) > 0 {
+		m := round(slices.Min(acc.windowReadings))
+		impact.PostWindowMin = &m
+	}
+	impact.DelayedHypoRate = round(float64(impact.DelayedHypoSessions) / float64(impact.Sessions) * 100)
+	return impact
+}
+
+func meanPtr(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	mean, _ := meanAndSD(values)
+	mean = round(mean)
+	return &mean
+}
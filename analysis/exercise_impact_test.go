@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// optional 格式化可缺失的数值，缺失时记为 -
+func optional(p *float64) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprint(*p)
+}
+
+// impactString 描述一组运动的汇总结果
+func impactString(impact ExerciseImpact) string {
+	return fmt.Sprintf("%s/%s %v sessions=%d duration=%v pre=%s post=%s delta=%s/%d window=%d mean=%s min=%s hypo=%d/%v%% inferred=%d",
+		impact.Type, impact.Intensity, impact.Names, impact.Sessions, impact.AvgDuration,
+		optional(impact.AvgPreGlucose), optional(impact.AvgPostGlucose), optional(impact.AvgDelta), impact.DeltaSessions,
+		impact.PostWindowReadings, optional(impact.PostWindowMean), optional(impact.PostWindowMin),
+		impact.DelayedHypoSessions, impact.DelayedHypoRate, impact.InferredUnitRecords)
+}
+
+func exercise(name, typ, intensity string, startMinutes, duration int, pre, post float32) model.ExerciseRecord {
+	return model.ExerciseRecord{
+		Type:        typ,
+		Name:        name,
+		Intensity:   intensity,
+		StartAt:     at(startMinutes),
+		EndAt:       at(startMinutes + duration),
+		Duration:    duration,
+		PreGlucose:  pre,
+		PostGlucose: post,
+	}
+}
+
+func TestAnalyzeExerciseImpact(t *testing.T) {
+	day := 24 * 60
+	exercises := []model.ExerciseRecord{
+		// 有氧运动两个场次：第二场缺少运动后血糖，窗口内也没有读数
+		exercise("快走", "aerobic", "moderate", 10*60, 30, 8, 6.5),
+		exercise("散步", "aerobic", "moderate", 16*60, 60, 7, 0),
+		// 运动前后血糖均缺失，只有窗口内读数
+		exercise("跑步", "aerobic", "high", day+8*60, 45, 0, 0),
+		exercise("力量训练", "resistance", "low", 14*60, 20, 9, 8),
+	}
+	inferred := reading(day+9*60, 6)
+	inferred.UnitInferred = true
+	records := []model.BloodGlucoseRecord{
+		// 快走窗口为 10:30 至 12:30，两端都包含
+		reading(10*60+29, 9),
+		reading(12*60+31, 3),
+		reading(11*60, 5),
+		reading(10*60+30, 6.5),
+		reading(12*60+30, 3.5),
+		// 散步窗口 17:00 至 19:00 之外
+		reading(19*60+10, 3),
+		inferred,
+	}
+	opts := ExerciseImpactOptions{PostWindow: 2 * time.Hour, HypoThreshold: 3.9}
+
+	var got []string
+	for _, impact := range AnalyzeExerciseImpact(exercises, records, opts) {
+		got = append(got, impactString(impact))
+	}
+	// 按平均变化升序，没有前后血糖差值的组排在最后
+	want := []string{
+		"aerobic/moderate [快走 散步] sessions=2 duration=45 pre=7.5 post=6.5 delta=-1.5/1 window=3 mean=5 min=3.5 hypo=1/50% inferred=0",
+		"resistance/low [力量训练] sessions=1 duration=20 pre=9 post=8 delta=-1/1 window=0 mean=- min=- hypo=0/0% inferred=0",
+		"aerobic/high [跑步] sessions=1 duration=45 pre=- post=- delta=-/0 window=1 mean=6 min=6 hypo=0/0% inferred=1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("impacts =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAnalyzeExerciseImpactNoExercises(t *testing.T) {
+	impacts := AnalyzeExerciseImpact(nil, hourlyReadings(5, 6), ExerciseImpactOptions{PostWindow: time.Hour, HypoThreshold: 3.9})
+	if impacts == nil || len(impacts) != 0 {
+		t.Errorf("AnalyzeExerciseImpact() = %+v, want empty slice", impacts)
+	}
+}
@@ -0,0 +1,155 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"time"
+)
+
+const (
+	EpisodeHypo  = "hypo"
+	EpisodeHyper = "hyper"
+
+	// 指尖血糖无法判断是否需要他人协助，以极低/极高读数（mmol/L）作为 3 级事件的近似
+	level3HypoThreshold  = 2.2
+	level3HyperThreshold = 16.7
+
+	// 夜间时段 [00:00, 06:00)
+	nocturnalEndHour = 6
+
+	DefaultEpisodeGap = 2 * time.Hour
+)
+
+// GlucoseEpisode 连续越界读数合并得到的一次低血糖或高血糖事件
+type GlucoseEpisode struct {
+	Kind            string    `json:"kind"`
+	Level           int       `json:"level"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationMinutes int       `json:"duration_minutes"`
+	// Extreme 低血糖事件为最低值，高血糖事件为最高值
+	Extreme   float64   `json:"extreme"`
+	ExtremeAt time.Time `json:"extreme_at"`
+	Readings  int       `json:"readings"`
+	Nocturnal bool      `json:"nocturnal"`
+	// UnitInferred 事件包含存储时未标明单位、按数值推断单位换算的读数
+	UnitInferred bool `json:"unit_inferred,omitempty"`
+	// Exercise 低血糖发生在运动期间或运动后窗口内时关联的运动记录
+	Exercise *model.ExerciseRecord `json:"exercise,omitempty"`
+}
+
+type EpisodeOptions struct {
+	Thresholds GlucoseThresholds
+	// MaxGap 相邻越界读数的最大间隔，超过则视为两次事件
+	MaxGap time.Duration
+	// ExerciseWindow 运动结束后仍视为运动相关低血糖的时长
+	ExerciseWindow time.Duration
+}
+
+type GlucoseEvents struct {
+	Start         time.Time         `json:"start"`
+	End           time.Time         `json:"end"`
+	Thresholds    GlucoseThresholds `json:"thresholds"`
+	HypoEpisodes  []GlucoseEpisode  `json:"hypo_episodes"`
+	HyperEpisodes []GlucoseEpisode  `json:"hyper_episodes"`
+}
+
+// DetectGlucoseEvents 识别低/高血糖事件，并将低血糖事件与运动记录关联
+func DetectGlucoseEvents(records []model.BloodGlucoseRecord, exercises []model.ExerciseRecord, opts EpisodeOptions, start, end time.Time) GlucoseEvents {
+	records = sortByMeasuredAt(records)
+
+	events := GlucoseEvents{
+		Start:         start,
+		End:           end,
+		Thresholds:    opts.Thresholds,
+		HypoEpisodes:  groupEpisodes(records, EpisodeHypo, opts.Thresholds, opts.MaxGap),
+		HyperEpisodes: groupEpisodes(records, EpisodeHyper, opts.Thresholds, opts.MaxGap),
+	}
+
+	for i := range events.HypoEpisodes {
+		events.HypoEpisodes[i].Exercise = findExercise(exercises, events.HypoEpisodes[i].Start, opts.ExerciseWindow)
+	}
+
+	return events
+}
+
+// 将按时间升序的读数中连续越界的部分合并为事件
+func groupEpisodes(records []model.BloodGlucoseRecord, kind string, thresholds GlucoseThresholds, maxGap time.Duration) []GlucoseEpisode {
+	episodes := []GlucoseEpisode{}
+	var current *GlucoseEpisode
+
+	for _, r := range records {
+		v := float64(r.Value)
+		level := episodeLevel(kind, v, thresholds)
+		if level == 0 {
+			current = nil
+			continue
+		}
+
+		if current != nil && r.MeasuredAt.Sub(current.End) <= maxGap {
+			current.UnitInferred = current.UnitInferred || r.UnitInferred
+			current.End = r.MeasuredAt
+			current.Readings++
+			current.Level = max(current.Level, level)
+			if (kind == EpisodeHypo && v < current.Extreme) || (kind == EpisodeHyper && v > current.Extreme) {
+				current.Extreme = round(v)
+				current.ExtremeAt = r.MeasuredAt
+			}
+		} else {
+			episodes = append(episodes, GlucoseEpisode{
+				Kind:         kind,
+				Level:        level,
+				Start:        r.MeasuredAt,
+				End:          r.MeasuredAt,
+				Extreme:      round(v),
+				ExtremeAt:    r.MeasuredAt,
+				Readings:     1,
+				Nocturnal:    r.MeasuredAt.Hour() < nocturnalEndHour,
+				UnitInferred: r.UnitInferred,
+			})
+			current = &episodes[len(episodes)-1]
+		}
+		current.DurationMinutes = int(current.End.Sub(current.Start).Minutes())
+	}
+
+	return episodes
+}
+
+// 返回读数对应的事件等级，未越界时返回 0
+func episodeLevel(kind string, v float64, thresholds GlucoseThresholds) int {
+	if kind == EpisodeHypo {
+		switch {
+		case v < model.ConvertGlucose(level3HypoThreshold, model.GlucoseUnitMmolL, thresholds.Unit):
+			return 3
+		case v < thresholds.VeryLow:
+			return 2
+		case v < thresholds.Low:
+			return 1
+		}
+		return 0
+	}
+
+	switch {
+	case v > model.ConvertGlucose(level3HyperThreshold, model.GlucoseUnitMmolL, thresholds.Unit):
+		return 3
+	case v > thresholds.VeryHigh:
+		return 2
+	case v > thresholds.High:
+		return 1
+	}
+	return 0
+}
+
+// 查找在 t 时刻进行中或在 t 之前 window 内结束的运动，多条时取最近开始的一条
+func findExercise(exercises []model.ExerciseRecord, t time.Time, window time.Duration) *model.ExerciseRecord {
+	var found *model.ExerciseRecord
+	for i := range exercises {
+		e := &exercises[i]
+		if e.StartAt.After(t) || t.Sub(e.EndAt) > window {
+			continue
+		}
+		if found == nil || e.StartAt.After(found.StartAt) {
+			found = e
+		}
+	}
+	return found
+}
@@ -0,0 +1,230 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// reading 构造 baseTime 之后 minutes 分钟的读数
+func reading(minutes int, value float32) model.BloodGlucoseRecord {
+	return model.BloodGlucoseRecord{Value: value, MeasuredAt: at(minutes)}
+}
+
+// episodeString 以相对 baseTime 的分钟数描述事件
+func episodeString(e GlucoseEpisode) string {
+	minutes := func(t time.Time) int { return int(t.Sub(baseTime).Minutes()) }
+	s := fmt.Sprintf("L%d %d-%d (%dmin) n=%d extreme=%v@%d",
+		e.Level, minutes(e.Start), minutes(e.End), e.DurationMinutes, e.Readings, e.Extreme, minutes(e.ExtremeAt))
+	if e.Nocturnal {
+		s += " nocturnal"
+	}
+	if e.UnitInferred {
+		s += " inferred"
+	}
+	return s
+}
+
+func TestGroupEpisodes(t *testing.T) {
+	inferred := reading(9*60+30, 3.6)
+	inferred.UnitInferred = true
+
+	tests := []struct {
+		name    string
+		records []model.BloodGlucoseRecord
+		kind    string
+		unit    string
+		want    []string
+	}{
+		{
+			name: "gap equal to max gap merges",
+			records: []model.BloodGlucoseRecord{
+				reading(8*60, 3.5), reading(10*60, 3.2),
+			},
+			kind: EpisodeHypo,
+			unit: model.GlucoseUnitMmolL,
+			want: []string{"L1 480-600 (120min) n=2 extreme=3.2@600"},
+		},
+		{
+			name: "gap above max gap splits",
+			records: []model.BloodGlucoseRecord{
+				reading(8*60, 3.5), reading(10*60+1, 3.2),
+			},
+			kind: EpisodeHypo,
+			unit: model.GlucoseUnitMmolL,
+			want: []string{
+				"L1 480-480 (0min) n=1 extreme=3.5@480",
+				"L1 601-601 (0min) n=1 extreme=3.2@601",
+			},
+		},
+		{
+			name: "in range reading ends episode",
+			records: []model.BloodGlucoseRecord{
+				reading(8*60, 3.5), reading(8*60+30, 5.0), reading(9*60, 3.4),
+			},
+			kind: EpisodeHypo,
+			unit: model.GlucoseUnitMmolL,
+			want: []string{
+				"L1 480-480 (0min) n=1 extreme=3.5@480",
+				"L1 540-540 (0min) n=1 extreme=3.4@540",
+			},
+		},
+		{
+			name: "hypo promoted to level 3 below 2.2 mmol/L",
+			records: []model.BloodGlucoseRecord{
+				reading(8*60, 3.5), reading(8*60+15, 2.9), reading(8*60+30, 2.1), reading(9*60, 3.0),
+			},
+			kind: EpisodeHypo,
+			unit: model.GlucoseUnitMmolL,
+			want: []string{"L3 480-540 (60min) n=4 extreme=2.1@510"},
+		},
+		{
+			// 2.2 mmol/L 约为 39.6 mg/dL
+			name: "hypo level 3 cut-off converted to mg/dL",
+			records: []model.BloodGlucoseRecord{
+				reading(8*60, 40), reading(12*60, 39),
+			},
+			kind: EpisodeHypo,
+			unit: model.GlucoseUnitMgdL,
+			want: []string{
+				"L2 480-480 (0min) n=1 extreme=40@480",
+				"L3 720-720 (0min) n=1 extreme=39@720",
+			},
+		},
+		{
+			name: "hyper levels in mmol/L",
+			records: []model.BloodGlucoseRecord{
+				reading(8*60, 11), reading(12*60, 15), reading(16*60, 16.8),
+			},
+			kind: EpisodeHyper,
+			unit: model.GlucoseUnitMmolL,
+			want: []string{
+				"L1 480-480 (0min) n=1 extreme=11@480",
+				"L2 720-720 (0min) n=1 extreme=15@720",
+				"L3 960-960 (0min) n=1 extreme=16.8@960",
+			},
+		},
+		{
+			// 16.7 mmol/L 约为 300.9 mg/dL
+			name: "hyper level 3 cut-off converted to mg/dL",
+			records: []model.BloodGlucoseRecord{
+				reading(8*60, 300), reading(9*60, 301), reading(10*60, 260),
+			},
+			kind: EpisodeHyper,
+			unit: model.GlucoseUnitMgdL,
+			want: []string{"L3 480-600 (120min) n=3 extreme=301@540"},
+		},
+		{
+			name: "nocturnal by episode start",
+			records: []model.BloodGlucoseRecord{
+				reading(5*60+59, 3.5), reading(6*60+30, 3.3),
+				reading(20*60, 5.0),
+				reading(24*60+6*60, 3.5),
+			},
+			kind: EpisodeHypo,
+			unit: model.GlucoseUnitMmolL,
+			want: []string{
+				"L1 359-390 (31min) n=2 extreme=3.3@390 nocturnal",
+				"L1 1800-1800 (0min) n=1 extreme=3.5@1800",
+			},
+		},
+		{
+			name: "inferred unit marks episode",
+			records: []model.BloodGlucoseRecord{
+				reading(9*60, 3.5), inferred,
+			},
+			kind: EpisodeHypo,
+			unit: model.GlucoseUnitMmolL,
+			want: []string{"L1 540-570 (30min) n=2 extreme=3.5@540 inferred"},
+		},
+		{
+			name:    "no readings out of range",
+			records: []model.BloodGlucoseRecord{reading(0, 5), reading(60, 8)},
+			kind:    EpisodeHyper,
+			unit:    model.GlucoseUnitMmolL,
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			episodes := groupEpisodes(tt.records, tt.kind, DefaultGlucoseThresholds(tt.unit), DefaultEpisodeGap)
+			if episodes == nil {
+				t.Fatal("groupEpisodes() = nil, want non-nil slice")
+			}
+			var got []string
+			for _, e := range episodes {
+				if e.Kind != tt.kind {
+					t.Errorf("Kind = %q, want %q", e.Kind, tt.kind)
+				}
+				got = append(got, episodeString(e))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("episodes = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindExercise(t *testing.T) {
+	exercises := []model.ExerciseRecord{
+		{ID: 1, StartAt: at(10 * 60), EndAt: at(11 * 60)},
+		{ID: 2, StartAt: at(12 * 60), EndAt: at(12*60 + 30)},
+	}
+	window := 2 * time.Hour
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want uint
+	}{
+		{"before any exercise", at(9*60 + 59), 0},
+		{"at exercise start", at(10 * 60), 1},
+		{"during exercise", at(10*60 + 30), 1},
+		{"after exercise within window", at(11*60 + 50), 1},
+		{"latest started exercise preferred", at(12*60 + 10), 2},
+		{"window end inclusive", at(14*60 + 30), 2},
+		{"after window", at(14*60 + 31), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got uint
+			if e := findExercise(exercises, tt.t, window); e != nil {
+				got = e.ID
+			}
+			if got != tt.want {
+				t.Errorf("findExercise() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectGlucoseEvents(t *testing.T) {
+	records := []model.BloodGlucoseRecord{
+		reading(15*60, 12),
+		reading(11*60+30, 3.2),
+		reading(22*60, 3.5),
+	}
+	exercises := []model.ExerciseRecord{{ID: 7, StartAt: at(10 * 60), EndAt: at(11 * 60)}}
+	opts := EpisodeOptions{
+		Thresholds:     DefaultGlucoseThresholds(model.GlucoseUnitMmolL),
+		MaxGap:         DefaultEpisodeGap,
+		ExerciseWindow: time.Hour,
+	}
+
+	events := DetectGlucoseEvents(records, exercises, opts, baseTime, at(24*60))
+
+	if len(events.HypoEpisodes) != 2 || len(events.HyperEpisodes) != 1 {
+		t.Fatalf("episodes = %d hypo, %d hyper; want 2 hypo, 1 hyper", len(events.HypoEpisodes), len(events.HyperEpisodes))
+	}
+	if e := events.HypoEpisodes[0].Exercise; e == nil || e.ID != 7 {
+		t.Errorf("first hypo exercise = %+v, want exercise 7", e)
+	}
+	if e := events.HypoEpisodes[1].Exercise; e != nil {
+		t.Errorf("second hypo exercise = %+v, want none", e)
+	}
+	if e := events.HyperEpisodes[0].Exercise; e != nil {
+		t.Errorf("hyper exercise = %+v, want none", e)
+	}
+}
@@ -0,0 +1,229 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// GlucoseThresholds 血糖分级阈值，默认值参考 TIR 国际共识，读数需与阈值使用同一单位
+type GlucoseThresholds struct {
+	Unit     string  `json:"unit"`
+	VeryLow  float64 `json:"very_low"`
+	Low      float64 `json:"low"`
+	High     float64 `json:"high"`
+	VeryHigh float64 `json:"very_high"`
+}
+
+// DefaultGlucoseThresholds 返回 unit 单位下的默认阈值
+func DefaultGlucoseThresholds(unit string) GlucoseThresholds {
+	if unit == model.GlucoseUnitMgdL {
+		return GlucoseThresholds{
+			Unit:     unit,
+			VeryLow:  54,
+			Low:      70,
+			High:     180,
+			VeryHigh: 250,
+		}
+	}
+	return GlucoseThresholds{
+		Unit:     model.GlucoseUnitMmolL,
+		VeryLow:  3.0,
+		Low:      3.9,
+		High:     10.0,
+		VeryHigh: 13.9,
+	}
+}
+
+// GlucoseSummary 一组血糖读数的统计结果，百分比字段均为读数占比
+type GlucoseSummary struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	SD    float64 `json:"sd"`
+	// CV 变异系数（%），≤36% 视为血糖稳定
+	CV float64 `json:"cv"`
+	// GMI 血糖管理指标（%），即估算糖化血红蛋白
+	GMI          float64 `json:"gmi"`
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	TimeVeryLow  float64 `json:"time_very_low"`
+	TimeLow      float64 `json:"time_low"`
+	TimeInRange  float64 `json:"time_in_range"`
+	TimeHigh     float64 `json:"time_high"`
+	TimeVeryHigh float64 `json:"time_very_high"`
+	// HypoEvents/HyperEvents 低/高血糖事件数，事件划分见 groupEpisodes
+	HypoEvents  int `json:"hypo_events"`
+	HyperEvents int `json:"hyper_events"`
+	// InferredUnitReadings 存储时未标明单位、按数值推断单位换算的读数数量
+	InferredUnitReadings int `json:"inferred_unit_readings,omitempty"`
+}
+
+// HourlyPercentiles 某一小时内读数的 AGP 百分位
+type HourlyPercentiles struct {
+	Hour  int     `json:"hour"`
+	Count int     `json:"count"`
+	P5    float64 `json:"p5"`
+	P25   float64 `json:"p25"`
+	P50   float64 `json:"p50"`
+	P75   float64 `json:"p75"`
+	P95   float64 `json:"p95"`
+}
+
+type GlucoseStatistics struct {
+	Start             time.Time                 `json:"start"`
+	End               time.Time                 `json:"end"`
+	Thresholds        GlucoseThresholds         `json:"thresholds"`
+	Overall           GlucoseSummary            `json:"overall"`
+	ByDiningStatus    map[string]GlucoseSummary `json:"by_dining_status"`
+	HourlyPercentiles []HourlyPercentiles       `json:"hourly_percentiles"`
+}
+
+// ComputeGlucoseStatistics 计算窗口内的血糖统计、分用餐状态统计和按小时的 AGP 百分位
+func ComputeGlucoseStatistics(records []model.BloodGlucoseRecord, thresholds GlucoseThresholds, start, end time.Time) GlucoseStatistics {
+	records = sortByMeasuredAt(records)
+
+	stats := GlucoseStatistics{
+		Start:          start,
+		End:            end,
+		Thresholds:     thresholds,
+		Overall:        Summarize(records, thresholds),
+		ByDiningStatus: make(map[string]GlucoseSummary),
+	}
+
+	groups := make(map[string][]model.BloodGlucoseRecord)
+	for _, r := range records {
+		groups[r.DiningStatus] = append(groups[r.DiningStatus], r)
+	}
+	for status, group := range groups {
+		stats.ByDiningStatus[status] = Summarize(group, thresholds)
+	}
+
+	stats.HourlyPercentiles = hourlyPercentiles(records)
+
+	return stats
+}
+
+// Summarize 计算一组按时间升序排列的读数的统计指标
+func Summarize(records []model.BloodGlucoseRecord, thresholds GlucoseThresholds) GlucoseSummary {
+	summary := GlucoseSummary{Count: len(records)}
+	if len(records) == 0 {
+		return summary
+	}
+
+	values := glucoseValues(records)
+	mean, sd := meanAndSD(values)
+	summary.Mean = round(mean)
+	summary.SD = round(sd)
+	summary.CV = round(sd / mean * 100)
+	summary.GMI = round(3.31 + 0.02392*model.ConvertGlucose(mean, thresholds.Unit, model.GlucoseUnitMgdL))
+	summary.Min = round(slices.Min(values))
+	summary.Max = round(slices.Max(values))
+
+	var veryLow, low, inRange, high, veryHigh int
+	for _, v := range values {
+		switch {
+		case v < thresholds.VeryLow:
+			veryLow++
+		case v < thresholds.Low:
+			low++
+		case v <= thresholds.High:
+			inRange++
+		case v <= thresholds.VeryHigh:
+			high++
+		default:
+			veryHigh++
+		}
+	}
+
+	summary.HypoEvents = len(groupEpisodes(records, EpisodeHypo, thresholds, DefaultEpisodeGap))
+	summary.HyperEvents = len(groupEpisodes(records, EpisodeHyper, thresholds, DefaultEpisodeGap))
+
+	for _, r := range records {
+		if r.UnitInferred {
+			summary.InferredUnitReadings++
+		}
+	}
+
+	n := float64(len(values))
+	summary.TimeVeryLow = round(float64(veryLow) / n * 100)
+	summary.TimeLow = round(float64(low) / n * 100)
+	summary.TimeInRange = round(float64(inRange) / n * 100)
+	summary.TimeHigh = round(float64(high) / n * 100)
+	summary.TimeVeryHigh = round(float64(veryHigh) / n * 100)
+
+	return summary
+}
+
+// 按测量时刻所在小时分组，计算每小时读数的 5/25/50/75/95 百分位，无读数的小时不返回
+func hourlyPercentiles(records []model.BloodGlucoseRecord) []HourlyPercentiles {
+	var byHour [24][]float64
+	for _, r := range records {
+		h := r.MeasuredAt.Hour()
+		byHour[h] = append(byHour[h], float64(r.Value))
+	}
+
+	var result []HourlyPercentiles
+	for h, values := range byHour {
+		if len(values) == 0 {
+			continue
+		}
+		sort.Float64s(values)
+		result = append(result, HourlyPercentiles{
+			Hour:  h,
+			Count: len(values),
+			P5:    round(percentile(values, 5)),
+			P25:   round(percentile(values, 25)),
+			P50:   round(percentile(values, 50)),
+			P75:   round(percentile(values, 75)),
+			P95:   round(percentile(values, 95)),
+		})
+	}
+	return result
+}
+
+func sortByMeasuredAt(records []model.BloodGlucoseRecord) []model.BloodGlucoseRecord {
+	sorted := append([]model.BloodGlucoseRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MeasuredAt.Before(sorted[j].MeasuredAt)
+	})
+	return sorted
+}
+
+func glucoseValues(records []model.BloodGlucoseRecord) []float64 {
+	values := make([]float64, len(records))
+	for i, r := range records {
+		values[i] = float64(r.Value)
+	}
+	return values
+}
+
+func meanAndSD(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+// 线性插值百分位，values 需已升序排列
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 1 {
+		return values[0]
+	}
+	pos := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return values[lower] + (values[upper]-values[lower])*(pos-float64(lower))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
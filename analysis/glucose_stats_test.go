@@ -0,0 +1,149 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+var baseTime = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// at 返回 baseTime 之后 minutes 分钟的时刻
+func at(minutes int) time.Time {
+	return baseTime.Add(time.Duration(minutes) * time.Minute)
+}
+
+// hourlyReadings 从 baseTime 开始每小时一条读数
+func hourlyReadings(values ...float32) []model.BloodGlucoseRecord {
+	records := make([]model.BloodGlucoseRecord, len(values))
+	for i, v := range values {
+		records[i] = model.BloodGlucoseRecord{Value: v, MeasuredAt: at(i * 60)}
+	}
+	return records
+}
+
+func TestSummarize(t *testing.T) {
+	inferred := hourlyReadings(50, 65, 100, 200, 300)
+	inferred[2].UnitInferred = true
+
+	tests := []struct {
+		name    string
+		records []model.BloodGlucoseRecord
+		unit    string
+		want    GlucoseSummary
+	}{
+		{
+			// 均值 8.0，总体标准差 √25.316，GMI = 3.31 + 0.02392 × 8.0 × 18.018
+			name:    "mmol/L one reading per range",
+			records: hourlyReadings(2.8, 3.5, 6.0, 12.0, 15.7),
+			unit:    model.GlucoseUnitMmolL,
+			want: GlucoseSummary{
+				Count: 5, Mean: 8, SD: 5.03, CV: 62.89, GMI: 6.76, Min: 2.8, Max: 15.7,
+				TimeVeryLow: 20, TimeLow: 20, TimeInRange: 20, TimeHigh: 20, TimeVeryHigh: 20,
+				HypoEvents: 1, HyperEvents: 1,
+			},
+		},
+		{
+			// 阈值本身：3.0 不低于极低阈值，3.9 和 10.0 在目标范围内，13.9 不高于极高阈值
+			name:    "mmol/L thresholds are range boundaries",
+			records: hourlyReadings(3.0, 3.9, 10.0, 13.9),
+			unit:    model.GlucoseUnitMmolL,
+			want: GlucoseSummary{
+				Count: 4, Mean: 7.7, SD: 4.48, CV: 58.17, GMI: 6.63, Min: 3, Max: 13.9,
+				TimeLow: 25, TimeInRange: 50, TimeHigh: 25,
+				HypoEvents: 1, HyperEvents: 1,
+			},
+		},
+		{
+			// 均值 143，总体标准差 √8896，GMI = 3.31 + 0.02392 × 143
+			name:    "mg/dL one reading per range",
+			records: inferred,
+			unit:    model.GlucoseUnitMgdL,
+			want: GlucoseSummary{
+				Count: 5, Mean: 143, SD: 94.32, CV: 65.96, GMI: 6.73, Min: 50, Max: 300,
+				TimeVeryLow: 20, TimeLow: 20, TimeInRange: 20, TimeHigh: 20, TimeVeryHigh: 20,
+				HypoEvents: 1, HyperEvents: 1,
+				InferredUnitReadings: 1,
+			},
+		},
+		{
+			name:    "empty window",
+			records: nil,
+			unit:    model.GlucoseUnitMmolL,
+			want:    GlucoseSummary{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.records, DefaultGlucoseThresholds(tt.unit))
+			if got != tt.want {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeGlucoseStatistics(t *testing.T) {
+	day := 24 * 60
+	records := []model.BloodGlucoseRecord{
+		// 08 时 4 条读数，乱序写入
+		{Value: 10, MeasuredAt: at(3*day + 8*60), DiningStatus: "after_meal"},
+		{Value: 4, MeasuredAt: at(8 * 60), DiningStatus: "fasting"},
+		{Value: 6, MeasuredAt: at(2*day + 8*60), DiningStatus: "fasting"},
+		{Value: 5, MeasuredAt: at(day + 8*60 + 30), DiningStatus: "fasting"},
+		// 22 时 1 条读数
+		{Value: 7, MeasuredAt: at(22 * 60), DiningStatus: "bedtime"},
+	}
+	thresholds := DefaultGlucoseThresholds(model.GlucoseUnitMmolL)
+
+	stats := ComputeGlucoseStatistics(records, thresholds, baseTime, at(4*day))
+
+	if stats.Overall.Count != 5 || stats.Overall.Mean != 6.4 {
+		t.Errorf("Overall = %+v, want count 5 and mean 6.4", stats.Overall)
+	}
+
+	counts := make(map[string]int)
+	for status, summary := range stats.ByDiningStatus {
+		counts[status] = summary.Count
+	}
+	wantCounts := map[string]int{"fasting": 3, "after_meal": 1, "bedtime": 1}
+	if len(counts) != len(wantCounts) {
+		t.Errorf("ByDiningStatus counts = %v, want %v", counts, wantCounts)
+	}
+	for status, n := range wantCounts {
+		if counts[status] != n {
+			t.Errorf("ByDiningStatus[%s].Count = %d, want %d", status, counts[status], n)
+		}
+	}
+	if got := stats.ByDiningStatus["fasting"].Mean; got != 5 {
+		t.Errorf("ByDiningStatus[fasting].Mean = %v, want 5", got)
+	}
+
+	// 08 时排序后为 4, 5, 6, 10，第 p 百分位位于 p/100 × 3 处线性插值
+	want := []HourlyPercentiles{
+		{Hour: 8, Count: 4, P5: 4.15, P25: 4.75, P50: 5.5, P75: 7, P95: 9.4},
+		{Hour: 22, Count: 1, P5: 7, P25: 7, P50: 7, P75: 7, P95: 7},
+	}
+	if !slices.Equal(stats.HourlyPercentiles, want) {
+		t.Errorf("HourlyPercentiles = %+v, want %+v", stats.HourlyPercentiles, want)
+	}
+}
+
+func TestComputeGlucoseStatisticsEmpty(t *testing.T) {
+	thresholds := DefaultGlucoseThresholds(model.GlucoseUnitMgdL)
+	stats := ComputeGlucoseStatistics(nil, thresholds, baseTime, at(60))
+
+	if stats.Overall != (GlucoseSummary{}) {
+		t.Errorf("Overall = %+v, want zero summary", stats.Overall)
+	}
+	if stats.ByDiningStatus == nil || len(stats.ByDiningStatus) != 0 {
+		t.Errorf("ByDiningStatus = %v, want empty map", stats.ByDiningStatus)
+	}
+	if len(stats.HourlyPercentiles) != 0 {
+		t.Errorf("HourlyPercentiles = %+v, want none", stats.HourlyPercentiles)
+	}
+	if stats.Thresholds != thresholds {
+		t.Errorf("Thresholds = %+v, want %+v", stats.Thresholds, thresholds)
+	}
+}
@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"math"
+	"time"
+)
+
+// DrugAdherence 单个用药方案在窗口内的依从性，按需用药没有应服次数，不计算比例
+type DrugAdherence struct {
+	RegimenID uint   `json:"regimen_id"`
+	Drug      string `json:"drug"`
+	Frequency string `json:"frequency"`
+	// ActiveDays 窗口内方案处于使用中的天数
+	ActiveDays float64 `json:"active_days"`
+	Expected   int     `json:"expected"`
+	Taken      int     `json:"taken"`
+	Late       int     `json:"late"`
+	Skipped    int     `json:"skipped"`
+	// Missing 应服但既未服药也未记录跳过的次数
+	Missing int `json:"missing"`
+	// Adherence 已服（含延迟）次数占应服次数的百分比，超过应服次数时记为 100
+	Adherence *float64 `json:"adherence,omitempty"`
+	// OnTime 按时服药次数占应服次数的百分比
+	OnTime *float64 `json:"on_time,omitempty"`
+}
+
+// AdherenceReport 窗口内各用药方案的依从性，Overall 为所有定时用药合计的依从性
+type AdherenceReport struct {
+	Start   time.Time       `json:"start"`
+	End     time.Time       `json:"end"`
+	Drugs   []DrugAdherence `json:"drugs"`
+	Overall *float64        `json:"overall,omitempty"`
+}
+
+// ComputeAdherence 按方案的用药频率和窗口内的使用天数推算应服次数，与服药记录对比；
+// 窗口内未使用的方案不计入，只统计方案使用期间的服药记录
+func ComputeAdherence(regimens []model.MedicationRegimen, doses []model.MedicationDose, start, end time.Time) AdherenceReport {
+	report := AdherenceReport{Start: start, End: end, Drugs: []DrugAdherence{}}
+
+	byRegimen := make(map[uint][]model.MedicationDose)
+	for _, d := range doses {
+		byRegimen[d.RegimenID] = append(byRegimen[d.RegimenID], d)
+	}
+
+	var expected, taken int
+	for _, r := range regimens {
+		from, to := activeWindow(r, start, end)
+		if !from.Before(to) {
+			continue
+		}
+
+		a := DrugAdherence{
+			RegimenID:  r.ID,
+			Drug:       r.Drug,
+			Frequency:  r.Frequency,
+			ActiveDays: round(to.Sub(from).Hours() / 24),
+			Expected:   int(math.Round(to.Sub(from).Hours() / 24 * model.DosesPerDay(r.Frequency))),
+		}
+		for _, d := range byRegimen[r.ID] {
+			if d.TakenAt.Before(from) || !d.TakenAt.Before(to) {
+				continue
+			}
+			switch d.Status {
+			case model.DoseStatusTaken:
+				a.Taken++
+			case model.DoseStatusLate:
+				a.Late++
+			case model.DoseStatusSkipped:
+				a.Skipped++
+			}
+		}
+
+		if a.Expected > 0 {
+			a.Missing = max(a.Expected-a.Taken-a.Late-a.Skipped, 0)
+			a.Adherence = percentage(a.Taken+a.Late, a.Expected)
+			a.OnTime = percentage(a.Taken, a.Expected)
+			expected += a.Expected
+			taken += min(a.Taken+a.Late, a.Expected)
+		}
+		report.Drugs = append(report.Drugs, a)
+	}
+
+	if expected > 0 {
+		report.Overall = percentage(taken, expected)
+	}
+	return report
+}
+
+// activeWindow 方案的用药区间与窗口的交集
+func activeWindow(r model.MedicationRegimen, start, end time.Time) (time.Time, time.Time) {
+	from, to := start, end
+	if r.StartDate.After(from) {
+		from = r.StartDate
+	}
+	if r.StopDate != nil && r.StopDate.Before(to) {
+		to = *r.StopDate
+	}
+	return from, to
+}
+
+func percentage(n, total int) *float64 {
+	p := round(min(float64(n)/float64(total), 1) * 100)
+	return &p
+}
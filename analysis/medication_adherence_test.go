@@ -0,0 +1,112 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// atDay 返回 baseTime 之后 days 天的时刻
+func atDay(days float64) time.Time {
+	return baseTime.Add(time.Duration(days * 24 * float64(time.Hour)))
+}
+
+func regimen(id uint, frequency string, startDay float64, stopDay *float64) model.MedicationRegimen {
+	r := model.MedicationRegimen{ID: id, Drug: fmt.Sprintf("药物%d", id), Frequency: frequency, StartDate: atDay(startDay)}
+	if stopDay != nil {
+		stop := atDay(*stopDay)
+		r.StopDate = &stop
+	}
+	return r
+}
+
+// doses 为方案生成 n 条同一状态的服药记录，从 firstDay 开始每隔 stepDays 天一条
+func doses(regimenID uint, status string, n int, firstDay, stepDays float64) []model.MedicationDose {
+	var result []model.MedicationDose
+	for i := range n {
+		result = append(result, model.MedicationDose{
+			RegimenID: regimenID,
+			Status:    status,
+			TakenAt:   atDay(firstDay + float64(i)*stepDays),
+		})
+	}
+	return result
+}
+
+func adherenceString(a DrugAdherence) string {
+	return fmt.Sprintf("%d %s days=%v expected=%d taken=%d late=%d skipped=%d missing=%d adherence=%s on_time=%s",
+		a.RegimenID, a.Frequency, a.ActiveDays, a.Expected, a.Taken, a.Late, a.Skipped, a.Missing,
+		optional(a.Adherence), optional(a.OnTime))
+}
+
+func TestComputeAdherence(t *testing.T) {
+	day := func(d float64) *float64 { return &d }
+	start, end := atDay(0), atDay(14)
+
+	regimens := []model.MedicationRegimen{
+		// 窗口开始前已在使用
+		regimen(1, "twice_daily", -30, nil),
+		// 窗口内开始使用：10.5 天 × 0.5 = 5.25 次，取整为 5
+		regimen(2, "every_other_day", 3.5, nil),
+		// 窗口内停用：10 天 × 1/7 ≈ 1.43 次，取整为 1
+		regimen(3, "weekly", -10, day(10)),
+		// 1.5 天 × 3 = 4.5 次，取整为 5
+		regimen(4, "three_times_daily", 12.5, nil),
+		regimen(5, "as_needed", -5, nil),
+		// 窗口开始前已停用或窗口结束后才开始，不计入
+		regimen(6, "once_daily", -20, day(-1)),
+		regimen(7, "once_daily", 14, nil),
+	}
+
+	var records []model.MedicationDose
+	// 方案 1：窗口开始时刻的记录计入，结束时刻和窗口之前的记录不计入
+	records = append(records, doses(1, model.DoseStatusTaken, 20, 0, 0.5)...)
+	records = append(records, doses(1, model.DoseStatusLate, 3, 10, 0.5)...)
+	records = append(records, doses(1, model.DoseStatusSkipped, 2, 12, 0.5)...)
+	records = append(records, doses(1, model.DoseStatusTaken, 1, -0.5, 0)...)
+	records = append(records, doses(1, model.DoseStatusTaken, 1, 14, 0)...)
+	// 方案 3：服药次数超过应服次数，停用后的记录不计入
+	records = append(records, doses(3, model.DoseStatusTaken, 2, 1, 7)...)
+	records = append(records, doses(3, model.DoseStatusTaken, 1, 11, 0)...)
+	// 方案 4：开始使用前的记录不计入
+	records = append(records, doses(4, model.DoseStatusTaken, 4, 12.6, 0.3)...)
+	records = append(records, doses(4, model.DoseStatusTaken, 1, 12, 0)...)
+	records = append(records, doses(5, model.DoseStatusTaken, 3, 1, 2)...)
+	records = append(records, doses(6, model.DoseStatusTaken, 1, 0.5, 0)...)
+
+	report := ComputeAdherence(regimens, records, start, end)
+
+	var got []string
+	for _, a := range report.Drugs {
+		got = append(got, adherenceString(a))
+	}
+	want := []string{
+		"1 twice_daily days=14 expected=28 taken=20 late=3 skipped=2 missing=3 adherence=82.14 on_time=71.43",
+		"2 every_other_day days=10.5 expected=5 taken=0 late=0 skipped=0 missing=5 adherence=0 on_time=0",
+		"3 weekly days=10 expected=1 taken=2 late=0 skipped=0 missing=0 adherence=100 on_time=100",
+		"4 three_times_daily days=1.5 expected=5 taken=4 late=0 skipped=0 missing=1 adherence=80 on_time=80",
+		"5 as_needed days=14 expected=0 taken=3 late=0 skipped=0 missing=0 adherence=- on_time=-",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("drugs =\n%q\nwant\n%q", got, want)
+	}
+
+	// 合计只含定时用药，超出应服次数的部分不计：(23 + 0 + 1 + 4) / (28 + 5 + 1 + 5)
+	if got := optional(report.Overall); got != "71.79" {
+		t.Errorf("Overall = %s, want 71.79", got)
+	}
+}
+
+func TestComputeAdherenceAsNeededOnly(t *testing.T) {
+	regimens := []model.MedicationRegimen{regimen(1, "as_needed", 0, nil)}
+	report := ComputeAdherence(regimens, doses(1, model.DoseStatusTaken, 2, 1, 1), atDay(0), atDay(7))
+
+	if len(report.Drugs) != 1 || report.Drugs[0].Taken != 2 {
+		t.Errorf("Drugs = %+v, want one as-needed regimen with 2 doses", report.Drugs)
+	}
+	if report.Overall != nil {
+		t.Errorf("Overall = %v, want nil", *report.Overall)
+	}
+}
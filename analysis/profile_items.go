@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"diabetes-care-mcp-server/model"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// 健康档案列表字段的分隔符，空白不作为分隔符，以免拆开“二甲双胍 0.5g”这类带剂量的条目
+const profileListSeparators = ",，、;；/\n"
+
+// 表示没有内容的条目
+var emptyProfileItems = []string{"无", "没有", "暂无", "不详", "none", "n/a", "na", "-"}
+
+// ProfileItem 健康档案自由文本列表中的一项，Entities 为其中识别出的图谱实体
+type ProfileItem struct {
+	Text     string
+	Entities []model.EntityNode
+}
+
+// ParseProfileList 将用药、过敏、并发症等自由文本按分隔符切分为条目，并识别每项中的实体
+func (d *EntityDictionary) ParseProfileList(text string) []ProfileItem {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return strings.ContainsRune(profileListSeparators, r)
+	})
+
+	var items []ProfileItem
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(emptyProfileItems, model.NormalizeEntityName(field)) {
+			continue
+		}
+		item := ProfileItem{Text: field}
+		for _, term := range d.Extract(field) {
+			item.Entities = append(item.Entities, term.Entities...)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// EntitiesOfType 返回条目中指定类型的实体
+func (item ProfileItem) EntitiesOfType(entityType string) []model.EntityNode {
+	var entities []model.EntityNode
+	for _, e := range item.Entities {
+		if e.Type == entityType {
+			entities = append(entities, e)
+		}
+	}
+	return entities
+}
+
+// Mentions 判断条目是否提及实体：条目中识别出了该实体；条目中没有识别出同类型的实体时，
+// 条目与实体名称、别名互相包含也视为提及，例如“青霉素过敏”提及“青霉素”
+func (item ProfileItem) Mentions(e model.EntityNode) bool {
+	recognized := false
+	for _, found := range item.Entities {
+		if found.ID != "" && found.ID == e.ID {
+			return true
+		}
+		recognized = recognized || found.Type == e.Type
+	}
+	if recognized {
+		return false
+	}
+
+	text := model.NormalizeEntityName(item.Text)
+	for _, name := range append([]string{e.Name}, e.Aliases...) {
+		name = model.NormalizeEntityName(name)
+		if utf8.RuneCountInString(name) < minDictionaryTermLength || utf8.RuneCountInString(text) < minDictionaryTermLength {
+			continue
+		}
+		if strings.Contains(text, name) || strings.Contains(name, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// aliasTextProperty 别名以空格拼接后存入的属性，纳入全文索引；全文索引不支持列表属性
+const aliasTextProperty = "alias_text"
+
+// applyAliases 读取别名词典并写入图谱
+func applyAliases(ctx context.Context, im *importer, path string) error {
+	aliases, err := dao.ReadAliases(path)
+	if err != nil {
+		return err
+	}
+
+	entities, unmatched, err := im.importAliases(ctx, aliases)
+	if err != nil {
+		return err
+	}
+	if len(unmatched) > 0 {
+		slog.Warn("Aliases without a matching entity", "count", len(unmatched), "names", unmatched)
+	}
+	slog.Info("Imported aliases", "aliases", len(aliases), "entities", entities)
+	return nil
+}
+
+// aliasRows 将别名按实体名称和类型分组，每组对应 UNWIND 的一行
+func aliasRows(aliases []model.EntityAlias) []map[string]any {
+	var rows []map[string]any
+	index := make(map[[2]string]int)
+	seen := make(map[string]bool)
+
+	for _, a := range aliases {
+		name := model.NormalizeEntityName(a.Name)
+		normalized := model.NormalizeEntityName(a.Alias)
+		if normalized == name || seen[name+"\x00"+a.Type+"\x00"+normalized] {
+			continue
+		}
+		seen[name+"\x00"+a.Type+"\x00"+normalized] = true
+
+		group := [2]string{name, a.Type}
+		i, ok := index[group]
+		if !ok {
+			i = len(rows)
+			index[group] = i
+			rows = append(rows, map[string]any{
+				"name":         name,
+				"display_name": strings.TrimSpace(a.Name),
+				"type":         a.Type,
+				"aliases":      []any{},
+			})
+		}
+		rows[i]["aliases"] = append(rows[i]["aliases"].([]any), map[string]any{
+			"alias":      strings.TrimSpace(a.Alias),
+			"normalized": normalized,
+		})
+	}
+	return rows
+}
+
+// importAliases 以别名词典替换实体上已有的别名，返回附加了别名的实体数及找不到对应实体的名称；
+// 别名词典通常很小，在单个事务中完成，避免检索时看到新旧别名混杂
+func (im *importer) importAliases(ctx context.Context, aliases []model.EntityAlias) (int64, []string, error) {
+	rows := aliasRows(aliases)
+
+	session := im.session(ctx)
+	defer session.Close(ctx)
+
+	type outcome struct {
+		entities  int64
+		unmatched []string
+	}
+	result, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		clear := fmt.Sprintf(`
+			MATCH (e:Entity)
+			WHERE e.aliases IS NOT NULL
+			REMOVE e.aliases, e.normalized_aliases, e.%s
+		`, aliasTextProperty)
+		if _, err := tx.Run(ctx, clear, nil); err != nil {
+			return nil, err
+		}
+
+		// 同一实体可能同时匹配带类型和不带类型的分组，合并后去重
+		attach := fmt.Sprintf(`
+			UNWIND $rows AS row
+			MATCH (e:Entity {normalized_name: row.name})
+			WHERE row.type = '' OR e.type = row.type
+			WITH e, collect(row) AS groups
+			WITH e, reduce(acc = [], g IN groups |
+				acc + [a IN g.aliases WHERE NOT a.normalized IN [x IN acc | x.normalized]]) AS aliases
+			SET e.aliases = [a IN aliases | a.alias],
+				e.normalized_aliases = [a IN aliases | a.normalized],
+				e.%s = reduce(text = '', a IN aliases | text + ' ' + a.alias)
+			RETURN count(e) AS entities
+		`, aliasTextProperty)
+		res, err := tx.Run(ctx, attach, map[string]any{"rows": rows})
+		if err != nil {
+			return nil, err
+		}
+		record, err := res.Single(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var out outcome
+		out.entities, _ = record.AsMap()["entities"].(int64)
+
+		unmatched := `
+			UNWIND $rows AS row
+			OPTIONAL MATCH (e:Entity {normalized_name: row.name})
+			WHERE row.type = '' OR e.type = row.type
+			WITH row, count(e) AS matched
+			WHERE matched = 0
+			RETURN row.display_name AS name, row.type AS type
+		`
+		res, err = tx.Run(ctx, unmatched, map[string]any{"rows": rows})
+		if err != nil {
+			return nil, err
+		}
+		for res.Next(ctx) {
+			m := res.Record().AsMap()
+			name, _ := m["name"].(string)
+			if typ, _ := m["type"].(string); typ != "" {
+				name += "(" + typ + ")"
+			}
+			out.unmatched = append(out.unmatched, name)
+		}
+		return out, res.Err()
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("error saving aliases: %v", err)
+	}
+
+	out := result.(outcome)
+	return out.entities, out.unmatched, nil
+}
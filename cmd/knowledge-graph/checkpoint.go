@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// checkpoint 记录已成功导入的文件及其内容摘要，导入中断后重新运行时跳过这些文件
+// 文件内容变化后摘要不同，会被重新导入
+type checkpoint struct {
+	path  string
+	Files map[string]string `json:"files"`
+}
+
+// loadCheckpoint 读取检查点文件，文件不存在时返回空检查点；path 为空时不记录进度
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{path: path, Files: make(map[string]string)}
+	if path == "" {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("error unmarshaling checkpoint %s: %v", path, err)
+	}
+	if c.Files == nil {
+		c.Files = make(map[string]string)
+	}
+	return c, nil
+}
+
+func (c *checkpoint) done(file, digest string) bool {
+	return c.Files[file] == digest
+}
+
+// mark 记录文件导入成功并立即落盘，先写临时文件再重命名，避免中断时损坏检查点
+func (c *checkpoint) mark(file, digest string) error {
+	c.Files[file] = digest
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("error writing checkpoint: %v", err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// reset 清空检查点，下次运行重新导入所有文件
+func (c *checkpoint) reset() error {
+	c.Files = make(map[string]string)
+	if c.path == "" {
+		return nil
+	}
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing checkpoint: %v", err)
+	}
+	return nil
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading file %s: %v", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("error reading file %s: %v", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
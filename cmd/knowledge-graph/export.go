@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"diabetes-care-mcp-server/config"
+	"diabetes-care-mcp-server/model"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	exportFormatDiaKG   = "diakg"
+	exportFormatCSV     = "csv"
+	exportFormatGraphML = "graphml"
+)
+
+var exportFormats = []string{exportFormatDiaKG, exportFormatCSV, exportFormatGraphML}
+
+// snapshot 从图谱中读出的实体、关系及文档
+type snapshot struct {
+	entities  []snapshotEntity
+	relations []snapshotRelation
+	documents []model.DiaKGDocument
+	// documentSources 文档节点的来源，来自这些来源的关系可由文档还原
+	documentSources map[string]bool
+}
+
+type snapshotEntity struct {
+	Key     string   `mapstructure:"key"`
+	Name    string   `mapstructure:"name"`
+	Type    string   `mapstructure:"type"`
+	Sources []string `mapstructure:"sources"`
+}
+
+type snapshotRelation struct {
+	Head        string   `mapstructure:"head"`
+	Type        string   `mapstructure:"type"`
+	Tail        string   `mapstructure:"tail"`
+	Weight      int      `mapstructure:"weight"`
+	Sources     []string `mapstructure:"sources"`
+	RelationIDs []string `mapstructure:"relation_ids"`
+	// Evidence 同时提及头尾实体的句子
+	Evidence []model.EvidenceSentence `mapstructure:"-"`
+}
+
+type exporter struct {
+	driver   neo4j.DriverWithContext
+	database string
+}
+
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path to config file")
+	database := fs.String("database", "", "source Neo4j database (default: server default database)")
+	format := fs.String("format", exportFormatCSV, "output format: diakg, csv or graphml")
+	output := fs.String("output", "-", "output file, - for stdout; for diakg an output directory")
+	provenance := fs.Bool("provenance", false, "include the sentences supporting each relation (csv and graphml; diakg always includes sentences)")
+	fs.Parse(args)
+
+	if !slices.Contains(exportFormats, *format) {
+		slog.Error("Invalid export format", "format", *format)
+		os.Exit(1)
+	}
+	if *format == exportFormatDiaKG && *output == "-" {
+		slog.Error("diakg export requires an output directory")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	driver, err := connect(ctx, cfg)
+	if err != nil {
+		slog.Error("Failed to connect to Neo4j", "err", err)
+		os.Exit(1)
+	}
+	defer driver.Close(ctx)
+
+	ex := &exporter{driver: driver, database: *database}
+	snap, err := ex.load(ctx, *provenance, *format == exportFormatDiaKG)
+	if err != nil {
+		slog.Error("Failed to read knowledge graph", "err", err)
+		os.Exit(1)
+	}
+
+	if *format == exportFormatDiaKG {
+		err = writeDiaKGSnapshot(*output, snap)
+	} else {
+		err = writeExportFile(*output, func(w io.Writer) error {
+			if *format == exportFormatGraphML {
+				return writeGraphML(w, snap, *provenance)
+			}
+			_, err := writeTriplesCSV(w, snap, nil, *provenance)
+			return err
+		})
+	}
+	if err != nil {
+		slog.Error("Failed to export knowledge graph", "err", err)
+		os.Exit(1)
+	}
+
+	slog.Info("Exported knowledge graph",
+		"format", *format,
+		"output", *output,
+		"entities", len(snap.entities),
+		"relations", len(snap.relations),
+		"documents", len(snap.documents),
+	)
+}
+
+// writeExportFile 将导出内容写入文件，path 为 - 时写入标准输出
+func writeExportFile(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// load 读取全部实体和关系，evidence 为真时读取关系的出处句子，documents 为真时读取文档结构
+func (ex *exporter) load(ctx context.Context, evidence, documents bool) (*snapshot, error) {
+	snap := &snapshot{documentSources: make(map[string]bool)}
+
+	entityQuery := `
+        MATCH (e:Entity)
+        RETURN e.key AS key, e.name AS name, e.type AS type, coalesce(e.sources, []) AS sources
+        ORDER BY key
+    `
+	err := ex.read(ctx, entityQuery, func(record map[string]any) error {
+		var e snapshotEntity
+		if err := mapstructure.Decode(record, &e); err != nil {
+			return fmt.Errorf("failed to decode entity: %v", err)
+		}
+		snap.entities = append(snap.entities, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	relationQuery := `
+        MATCH (h:Entity)-[r]->(t:Entity)
+        RETURN
+            h.key AS head,
+            type(r) AS type,
+            t.key AS tail,
+            coalesce(r.weight, 1) AS weight,
+            coalesce(r.sources, []) AS sources,
+            coalesce(r.relation_ids, []) AS relation_ids
+        ORDER BY head, type, tail
+    `
+	err = ex.read(ctx, relationQuery, func(record map[string]any) error {
+		var r snapshotRelation
+		if err := mapstructure.Decode(record, &r); err != nil {
+			return fmt.Errorf("failed to decode relation: %v", err)
+		}
+		snap.relations = append(snap.relations, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if evidence {
+		if err := ex.loadEvidence(ctx, snap); err != nil {
+			return nil, err
+		}
+	}
+	if documents {
+		if err := ex.loadDocuments(ctx, snap); err != nil {
+			return nil, err
+		}
+	}
+
+	return snap, nil
+}
+
+// loadEvidence 以同时提及头尾实体的句子作为关系的出处
+func (ex *exporter) loadEvidence(ctx context.Context, snap *snapshot) error {
+	query := `
+        MATCH (d:Document)-[:CONTAINS_PARAGRAPH]->(p:Paragraph)-[:CONTAINS_SENTENCE]->(s:Sentence)
+        MATCH (s)-[:CONTAINS_ENTITY]->(h:Entity)-[r]->(t:Entity)<-[:CONTAINS_ENTITY]-(s)
+        RETURN h.key AS head, type(r) AS type, t.key AS tail, collect(DISTINCT {
+            doc_id: d.doc_id,
+            paragraph_id: p.paragraph_id,
+            sentence_id: s.sentence_id,
+            text: s.text
+        }) AS sentences
+    `
+
+	index := make(map[[3]string]*snapshotRelation, len(snap.relations))
+	for i := range snap.relations {
+		r := &snap.relations[i]
+		index[[3]string{r.Head, r.Type, r.Tail}] = r
+	}
+
+	return ex.read(ctx, query, func(record map[string]any) error {
+		head, _ := record["head"].(string)
+		typ, _ := record["type"].(string)
+		tail, _ := record["tail"].(string)
+		r, ok := index[[3]string{head, typ, tail}]
+		if !ok {
+			return nil
+		}
+
+		sentences, _ := record["sentences"].([]any)
+		for _, item := range sentences {
+			s, _ := item.(map[string]any)
+			docID, _ := s["doc_id"].(string)
+			paraID, _ := s["paragraph_id"].(string)
+			sentenceID, _ := s["sentence_id"].(string)
+			text, _ := s["text"].(string)
+			r.Evidence = append(r.Evidence, model.EvidenceSentence{
+				DocID:       docID,
+				ParagraphID: paraID,
+				SentenceID:  sentenceID,
+				Text:        text,
+			})
+		}
+		slices.SortFunc(r.Evidence, func(a, b model.EvidenceSentence) int {
+			return naturalCompare(a.SentenceID, b.SentenceID)
+		})
+		return nil
+	})
+}
+
+// documentMention 句子中的一次实体标注
+type documentMention struct {
+	EntityID string `mapstructure:"entity_id"`
+	Key      string `mapstructure:"key"`
+	Name     string `mapstructure:"name"`
+	Type     string `mapstructure:"type"`
+	StartIdx int    `mapstructure:"start_idx"`
+	EndIdx   int    `mapstructure:"end_idx"`
+}
+
+// documentSentence 文档结构查询的一行，对应一个句子及其实体标注
+type documentSentence struct {
+	DocID       string            `mapstructure:"doc_id"`
+	Source      string            `mapstructure:"source"`
+	ParagraphID string            `mapstructure:"paragraph_id"`
+	Paragraph   string            `mapstructure:"paragraph"`
+	SentenceID  string            `mapstructure:"sentence_id"`
+	Sentence    string            `mapstructure:"sentence"`
+	Mentions    []documentMention `mapstructure:"mentions"`
+}
+
+// loadDocuments 读取文档、段落、句子及句中的实体标注，并还原句中的关系标注
+func (ex *exporter) loadDocuments(ctx context.Context, snap *snapshot) error {
+	query := `
+        MATCH (d:Document)-[:CONTAINS_PARAGRAPH]->(p:Paragraph)-[:CONTAINS_SENTENCE]->(s:Sentence)
+        OPTIONAL MATCH (s)-[m:CONTAINS_ENTITY]->(e:Entity)
+        WITH d, p, s, collect(CASE WHEN e IS NULL THEN NULL ELSE {
+            entity_id: m.entity_id,
+            key: e.key,
+            name: e.name,
+            type: e.type,
+            start_idx: m.start_idx,
+            end_idx: m.end_idx
+        } END) AS mentions
+        RETURN
+            d.doc_id AS doc_id,
+            coalesce(d.source, $defaultSource) AS source,
+            p.paragraph_id AS paragraph_id,
+            p.text AS paragraph,
+            s.sentence_id AS sentence_id,
+            s.text AS sentence,
+            mentions
+    `
+
+	var rows []documentSentence
+	err := ex.readWithParams(ctx, query, map[string]any{"defaultSource": model.DiaKGSource}, func(record map[string]any) error {
+		var row documentSentence
+		if err := mapstructure.Decode(record, &row); err != nil {
+			return fmt.Errorf("failed to decode sentence: %v", err)
+		}
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		snap.documentSources[row.Source] = true
+	}
+	snap.documents = buildDocuments(rows, snap.relations)
+	return nil
+}
+
+func (ex *exporter) read(ctx context.Context, query string, handle func(map[string]any) error) error {
+	return ex.readWithParams(ctx, query, nil, handle)
+}
+
+// readWithParams 在只读会话中执行查询，逐条回调结果记录
+func (ex *exporter) readWithParams(ctx context.Context, query string, params map[string]any, handle func(map[string]any) error) error {
+	session := ex.driver.NewSession(ctx, neo4j.SessionConfig{
+		AccessMode:   neo4j.AccessModeRead,
+		DatabaseName: ex.database,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %v", err)
+	}
+	for result.Next(ctx) {
+		if err := handle(result.Record().AsMap()); err != nil {
+			return err
+		}
+	}
+	if err := result.Err(); err != nil {
+		return fmt.Errorf("failed to process query results: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// writeTriplesCSV 输出 CSV 三元组，每个来源一行，表头与导入格式一致，可直接重新导入；
+// keep 不为空时只输出其接受的来源
+func writeTriplesCSV(w io.Writer, snap *snapshot, keep func(source string) bool, provenance bool) (int, error) {
+	entities := snap.entityIndex()
+
+	cw := csv.NewWriter(w)
+	header := []string{"head", "head_type", "relation", "tail", "tail_type", "source", "weight"}
+	if provenance {
+		header = append(header, "sentence_ids", "evidence")
+	}
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	rows := 0
+	for _, r := range snap.relations {
+		head, tail := entities.lookup(r.Head), entities.lookup(r.Tail)
+		sources := r.Sources
+		if len(sources) == 0 {
+			sources = []string{""}
+		}
+		for _, source := range sources {
+			if keep != nil && !keep(source) {
+				continue
+			}
+			record := []string{head.Name, head.Type, r.Type, tail.Name, tail.Type, source, strconv.Itoa(r.Weight)}
+			if provenance {
+				ids, texts := r.evidenceColumns()
+				record = append(record, ids, texts)
+			}
+			if err := cw.Write(record); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+	}
+
+	cw.Flush()
+	return rows, cw.Error()
+}
+
+// graphML GraphML 文档结构，只包含导出所需的元素
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeGraphML 输出 GraphML，节点 ID 为规范实体键，多值属性以分号分隔
+func writeGraphML(w io.Writer, snap *snapshot, provenance bool) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", Name: "name", Type: "string"},
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "sources", For: "node", Name: "sources", Type: "string"},
+			{ID: "relation", For: "edge", Name: "type", Type: "string"},
+			{ID: "weight", For: "edge", Name: "weight", Type: "int"},
+			{ID: "relation_sources", For: "edge", Name: "sources", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: "diabetes-kg", EdgeDefault: "directed"},
+	}
+	if provenance {
+		doc.Keys = append(doc.Keys,
+			graphMLKey{ID: "sentence_ids", For: "edge", Name: "sentence_ids", Type: "string"},
+			graphMLKey{ID: "evidence", For: "edge", Name: "evidence", Type: "string"},
+		)
+	}
+
+	for _, e := range snap.entities {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: e.Key,
+			Data: []graphMLData{
+				{Key: "name", Value: e.Name},
+				{Key: "type", Value: e.Type},
+				{Key: "sources", Value: strings.Join(e.Sources, ";")},
+			},
+		})
+	}
+	for i, r := range snap.relations {
+		edge := graphMLEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: r.Head,
+			Target: r.Tail,
+			Data: []graphMLData{
+				{Key: "relation", Value: r.Type},
+				{Key: "weight", Value: strconv.Itoa(r.Weight)},
+				{Key: "relation_sources", Value: strings.Join(r.Sources, ";")},
+			},
+		}
+		if provenance {
+			ids, texts := r.evidenceColumns()
+			edge.Data = append(edge.Data,
+				graphMLData{Key: "sentence_ids", Value: ids},
+				graphMLData{Key: "evidence", Value: texts},
+			)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// writeDiaKGSnapshot 将每个文档写为 <doc_id>.json，来源不属于任何文档的关系写入 triples.csv，
+// 输出目录可直接作为导入或内存图谱的数据目录
+func writeDiaKGSnapshot(dir string, snap *snapshot) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, doc := range snap.documents {
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling document %s: %v", doc.DocID, err)
+		}
+		name := strings.NewReplacer("/", "_", `\`, "_").Replace(doc.DocID) + ".json"
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(dir, dao.SnapshotTriplesFile)
+	var rows int
+	err := writeExportFile(path, func(w io.Writer) error {
+		var err error
+		rows, err = writeTriplesCSV(w, snap, func(source string) bool {
+			return !snap.documentSources[source]
+		}, false)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// 所有关系都来自文档时不保留只有表头的文件
+	if rows == 0 {
+		return os.Remove(path)
+	}
+	return nil
+}
+
+// buildDocuments 由文档结构查询的结果还原 DiaKG 文档；
+// 关系标注按句中实体对匹配图谱关系上记录的 <doc_id>/<relation_id>
+func buildDocuments(rows []documentSentence, relations []snapshotRelation) []model.DiaKGDocument {
+	byPair := make(map[[2]string][]*snapshotRelation)
+	for i := range relations {
+		r := &relations[i]
+		byPair[[2]string{r.Head, r.Tail}] = append(byPair[[2]string{r.Head, r.Tail}], r)
+	}
+	used := make(map[string]bool)
+
+	type paragraph struct {
+		model.DiaKGParagraph
+		rows []documentSentence
+	}
+	type document struct {
+		id, source string
+		paragraphs map[string]*paragraph
+	}
+	documents := make(map[string]*document)
+	for _, row := range rows {
+		doc, ok := documents[row.DocID]
+		if !ok {
+			doc = &document{id: row.DocID, source: row.Source, paragraphs: make(map[string]*paragraph)}
+			documents[row.DocID] = doc
+		}
+		para, ok := doc.paragraphs[row.ParagraphID]
+		if !ok {
+			para = &paragraph{DiaKGParagraph: model.DiaKGParagraph{ParagraphID: row.ParagraphID, Paragraph: row.Paragraph}}
+			doc.paragraphs[row.ParagraphID] = para
+		}
+		para.rows = append(para.rows, row)
+	}
+
+	var result []model.DiaKGDocument
+	for _, doc := range documents {
+		out := model.DiaKGDocument{DocID: doc.id}
+		ids := make([]string, 0, len(doc.paragraphs))
+		for id := range doc.paragraphs {
+			ids = append(ids, id)
+		}
+		slices.SortFunc(ids, naturalCompare)
+
+		// 按段落和句子顺序匹配关系，使同一实体对的关系 ID 分配稳定
+		for _, id := range ids {
+			para := doc.paragraphs[id]
+			sentences := make([]model.DiaKGSentence, len(para.rows))
+			for i, row := range para.rows {
+				sentences[i] = buildSentence(row)
+			}
+			order := make([]int, len(sentences))
+			for i := range order {
+				order[i] = i
+			}
+			slices.SortFunc(order, func(a, b int) int {
+				if sentences[a].StartIdx != sentences[b].StartIdx {
+					return sentences[a].StartIdx - sentences[b].StartIdx
+				}
+				return naturalCompare(sentences[a].SentenceID, sentences[b].SentenceID)
+			})
+			for _, i := range order {
+				sentence := sentences[i]
+				sentence.Relations = matchRelations(doc.id, doc.source, para.rows[i].Mentions, byPair, used)
+				para.Sentences = append(para.Sentences, sentence)
+			}
+			out.Paragraphs = append(out.Paragraphs, para.DiaKGParagraph)
+		}
+		result = append(result, out)
+	}
+	slices.SortFunc(result, func(a, b model.DiaKGDocument) int {
+		return naturalCompare(a.DocID, b.DocID)
+	})
+	return result
+}
+
+// buildSentence 还原句子在段落中的位置及实体标注，偏移量按字符计
+func buildSentence(row documentSentence) model.DiaKGSentence {
+	sentence := model.DiaKGSentence{
+		SentenceID: row.SentenceID,
+		Sentence:   row.Sentence,
+		Entities:   []model.DiaKGEntity{},
+		Relations:  []model.DiaKGRelation{},
+	}
+	if i := strings.Index(row.Paragraph, row.Sentence); i >= 0 {
+		sentence.StartIdx = utf8.RuneCountInString(row.Paragraph[:i])
+		sentence.EndIdx = sentence.StartIdx + utf8.RuneCountInString(row.Sentence)
+	}
+
+	text, paragraph := []rune(row.Sentence), []rune(row.Paragraph)
+	for _, m := range row.Mentions {
+		sentence.Entities = append(sentence.Entities, model.DiaKGEntity{
+			EntityID:   m.EntityID,
+			Entity:     mentionText(m, text, paragraph),
+			EntityType: m.Type,
+			StartIdx:   m.StartIdx,
+			EndIdx:     m.EndIdx,
+		})
+	}
+	slices.SortFunc(sentence.Entities, func(a, b model.DiaKGEntity) int {
+		if a.StartIdx != b.StartIdx {
+			return a.StartIdx - b.StartIdx
+		}
+		return naturalCompare(a.EntityID, b.EntityID)
+	})
+	return sentence
+}
+
+// mentionText 标注的原文：偏移量处的文本与规范名称归一化后一致时取原文，否则取规范名称
+func mentionText(m documentMention, sentence, paragraph []rune) string {
+	want := model.NormalizeEntityName(m.Name)
+	for _, text := range [][]rune{sentence, paragraph} {
+		if m.StartIdx < 0 || m.EndIdx > len(text) || m.StartIdx > m.EndIdx {
+			continue
+		}
+		if span := string(text[m.StartIdx:m.EndIdx]); model.NormalizeEntityName(span) == want {
+			return span
+		}
+	}
+	return m.Name
+}
+
+// matchRelations 为句中的实体对找回该文档的关系标注，每个关系 ID 只使用一次
+func matchRelations(docID, source string, mentions []documentMention, byPair map[[2]string][]*snapshotRelation, used map[string]bool) []model.DiaKGRelation {
+	mentions = slices.Clone(mentions)
+	slices.SortFunc(mentions, func(a, b documentMention) int {
+		if a.StartIdx != b.StartIdx {
+			return a.StartIdx - b.StartIdx
+		}
+		return naturalCompare(a.EntityID, b.EntityID)
+	})
+
+	relations := []model.DiaKGRelation{}
+	for _, head := range mentions {
+		for _, tail := range mentions {
+			if head.EntityID == tail.EntityID {
+				continue
+			}
+			for _, r := range byPair[[2]string{head.Key, tail.Key}] {
+				if !slices.Contains(r.Sources, source) {
+					continue
+				}
+				for _, id := range r.RelationIDs {
+					relationID, ok := strings.CutPrefix(id, docID+"/")
+					if !ok || used[id] {
+						continue
+					}
+					used[id] = true
+					relations = append(relations, model.DiaKGRelation{
+						RelationType: r.Type,
+						RelationID:   relationID,
+						HeadEntityID: head.EntityID,
+						TailEntityID: tail.EntityID,
+					})
+					break
+				}
+			}
+		}
+	}
+	slices.SortFunc(relations, func(a, b model.DiaKGRelation) int {
+		return naturalCompare(a.RelationID, b.RelationID)
+	})
+	return relations
+}
+
+// entityIndex 按规范实体键索引的实体
+type entityIndex map[string]snapshotEntity
+
+func (s *snapshot) entityIndex() entityIndex {
+	index := make(entityIndex, len(s.entities))
+	for _, e := range s.entities {
+		index[e.Key] = e
+	}
+	return index
+}
+
+// lookup 查找实体，缺失时由键还原类型和名称
+func (idx entityIndex) lookup(key string) snapshotEntity {
+	if e, ok := idx[key]; ok {
+		return e
+	}
+	entityType, name, _ := strings.Cut(key, ":")
+	return snapshotEntity{Key: key, Name: name, Type: entityType}
+}
+
+// evidenceColumns 出处句子 ID 以分号分隔，句子文本以换行分隔
+func (r snapshotRelation) evidenceColumns() (string, string) {
+	ids := make([]string, len(r.Evidence))
+	texts := make([]string, len(r.Evidence))
+	for i, s := range r.Evidence {
+		ids[i] = s.DocID + "/" + s.SentenceID
+		texts[i] = s.Text
+	}
+	return strings.Join(ids, ";"), strings.Join(texts, "\n")
+}
+
+// naturalCompare 比较 ID，其中的数字按数值比较，使 S2 排在 S10 之前
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if len(na) != len(nb) {
+				return len(na) - len(nb)
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		ra, sa := utf8.DecodeRuneInString(a)
+		rb, sb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			return int(ra) - int(rb)
+		}
+		a, b = a[sa:], b[sb:]
+	}
+	return len(a) - len(b)
+}
+
+func leadingDigits(s string) string {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 {
+		return s
+	}
+	return s[:i]
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"diabetes-care-mcp-server/embedding"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const defaultBatchSize = 500
+
+// 图谱节点的唯一性约束，MERGE 依赖这些约束对应的索引；
+// 段落和句子 ID 只在文档内唯一，以文档 ID 加段落或句子 ID 组成的 key 区分
+var constraints = []struct{ label, property string }{
+	{"Document", "doc_id"},
+	{"Paragraph", "key"},
+	{"Sentence", "key"},
+	{"Entity", "key"},
+}
+
+// 旧版导入程序创建的约束，要求段落和句子 ID 全局唯一，与按文档区分的节点冲突
+var obsoleteConstraints = []string{
+	"paragraph_paragraph_id_unique",
+	"sentence_sentence_id_unique",
+}
+
+// graphBatch 一个文件中待写入的节点与关系，每行对应 UNWIND 的一个元素
+type graphBatch struct {
+	documents  []map[string]any
+	paragraphs []map[string]any
+	sentences  []map[string]any
+	entities   []map[string]any
+	mentions   []map[string]any
+	// relations 按关系类型分组，关系类型无法参数化
+	relations map[string][]map[string]any
+	// rejected 不符合 DiaKG 标注体系而未写入的实体和关系
+	rejected []rejection
+	// seen 已加入 entities 的实体键与来源
+	seen map[string]bool
+}
+
+// rejection 一条被拒绝写入的实体或关系
+type rejection struct {
+	File   string `json:"file"`
+	Kind   string `json:"kind"`
+	DocID  string `json:"doc_id,omitempty"`
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// importer 以 UNWIND 批量写入图谱，所有写入均基于 MERGE，重复导入同一文件结果不变
+type importer struct {
+	driver neo4j.DriverWithContext
+	// database 写入的目标数据库，为空时使用服务端默认数据库
+	database  string
+	batchSize int
+	// embedder 为空时不计算向量
+	embedder embedding.Embedder
+}
+
+func (im *importer) session(ctx context.Context) neo4j.SessionWithContext {
+	return im.driver.NewSession(ctx, neo4j.SessionConfig{
+		AccessMode:   neo4j.AccessModeWrite,
+		DatabaseName: im.database,
+	})
+}
+
+// wipe 删除图谱中已导入的全部节点和关系，分批提交以免单个事务过大
+func (im *importer) wipe(ctx context.Context) error {
+	session := im.session(ctx)
+	defer session.Close(ctx)
+
+	// CALL ... IN TRANSACTIONS 只能在自动提交事务中执行
+	query := `
+		MATCH (n)
+		WHERE n:Document OR n:Paragraph OR n:Sentence OR n:Entity
+		CALL { WITH n DETACH DELETE n } IN TRANSACTIONS OF $batch ROWS
+	`
+	result, err := session.Run(ctx, query, map[string]any{"batch": im.batchSize})
+	if err != nil {
+		return fmt.Errorf("failed to wipe graph: %v", err)
+	}
+	if _, err := result.Consume(ctx); err != nil {
+		return fmt.Errorf("failed to wipe graph: %v", err)
+	}
+	return nil
+}
+
+// legacyNodeCount 统计没有 key 属性的实体、段落和句子节点数，这些节点由旧版导入程序写入：
+// 实体按 entity_id 区分，段落和句子按全局的 paragraph_id、sentence_id 区分
+func (im *importer) legacyNodeCount(ctx context.Context) (int64, error) {
+	session := im.session(ctx)
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (n)
+		WHERE (n:Entity OR n:Paragraph OR n:Sentence) AND n.key IS NULL
+		RETURN count(n) AS count
+	`
+	result, err := session.Run(ctx, query, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count legacy nodes: %v", err)
+	}
+	record, err := result.Single(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count legacy nodes: %v", err)
+	}
+	count, _ := record.Get("count")
+	n, _ := count.(int64)
+	return n, nil
+}
+
+// createConstraints 删除旧版约束并创建唯一性约束，已存在时跳过
+func (im *importer) createConstraints(ctx context.Context) error {
+	session := im.session(ctx)
+	defer session.Close(ctx)
+
+	for _, name := range obsoleteConstraints {
+		if _, err := session.Run(ctx, fmt.Sprintf("DROP CONSTRAINT %s IF EXISTS", name), nil); err != nil {
+			return fmt.Errorf("failed to drop constraint %s: %v", name, err)
+		}
+	}
+	for _, c := range constraints {
+		query := fmt.Sprintf(
+			"CREATE CONSTRAINT %s_%s_unique IF NOT EXISTS FOR (n:%s) REQUIRE n.%s IS UNIQUE",
+			strings.ToLower(c.label), c.property, c.label, c.property,
+		)
+		if _, err := session.Run(ctx, query, nil); err != nil {
+			return fmt.Errorf("failed to create constraint on %s.%s: %v", c.label, c.property, err)
+		}
+	}
+	return nil
+}
+
+// importDocument 写入一个 DiaKG 文档，返回因类型不合法而未写入的实体和关系
+func (im *importer) importDocument(ctx context.Context, doc model.DiaKGDocument, source string) ([]rejection, error) {
+	b := newDocumentBatch(doc, source)
+	return b.rejected, im.write(ctx, b)
+}
+
+// importTriples 写入外部数据集的三元组，返回不合法而未写入的三元组
+func (im *importer) importTriples(ctx context.Context, triples []model.KGTriple, defaultSource string) ([]rejection, error) {
+	b := newTripleBatch(triples, defaultSource)
+	return b.rejected, im.write(ctx, b)
+}
+
+func newGraphBatch() *graphBatch {
+	return &graphBatch{
+		relations: make(map[string][]map[string]any),
+		seen:      make(map[string]bool),
+	}
+}
+
+// newDocumentBatch 将文档展开为待写入的行，实体标注合并为规范实体
+// 类型不在标注体系中的实体及与其相连的关系、类型未知的关系均不写入
+func newDocumentBatch(doc model.DiaKGDocument, source string) *graphBatch {
+	b := newGraphBatch()
+	b.documents = []map[string]any{{"doc_id": doc.DocID, "source": source}}
+
+	// 标注中的实体 ID 只在文档内唯一，关系通过它映射到规范实体
+	keys := make(map[string]string)
+	for _, para := range doc.Paragraphs {
+		for _, sentence := range para.Sentences {
+			for _, entity := range sentence.Entities {
+				if !model.IsDiaKGEntityType(entity.EntityType) {
+					b.reject("entity", doc.DocID, entity.EntityID, entity.EntityType, "unknown entity type")
+					continue
+				}
+				keys[entity.EntityID] = model.CanonicalEntityKey(entity.Entity, entity.EntityType)
+			}
+		}
+	}
+
+	for _, para := range doc.Paragraphs {
+		paragraphKey := model.DiaKGPassageKey(doc.DocID, para.ParagraphID)
+		b.paragraphs = append(b.paragraphs, map[string]any{
+			"key":          paragraphKey,
+			"doc_id":       doc.DocID,
+			"paragraph_id": para.ParagraphID,
+			"text":         para.Paragraph,
+		})
+
+		for _, sentence := range para.Sentences {
+			sentenceKey := model.DiaKGPassageKey(doc.DocID, sentence.SentenceID)
+			b.sentences = append(b.sentences, map[string]any{
+				"key":           sentenceKey,
+				"paragraph_key": paragraphKey,
+				"doc_id":        doc.DocID,
+				"sentence_id":   sentence.SentenceID,
+				"text":          sentence.Sentence,
+			})
+
+			for _, entity := range sentence.Entities {
+				key, ok := keys[entity.EntityID]
+				if !ok {
+					continue
+				}
+				b.addEntity(entity.Entity, entity.EntityType, source)
+				b.mentions = append(b.mentions, map[string]any{
+					"sentence_key": sentenceKey,
+					"entity_id":    entity.EntityID,
+					"key":          key,
+					"start_idx":    entity.StartIdx,
+					"end_idx":      entity.EndIdx,
+				})
+			}
+
+			for _, relation := range sentence.Relations {
+				if !model.IsDiaKGRelationType(relation.RelationType) {
+					b.reject("relation", doc.DocID, relation.RelationID, relation.RelationType, "unknown relation type")
+					continue
+				}
+				headKey, ok1 := keys[relation.HeadEntityID]
+				tailKey, ok2 := keys[relation.TailEntityID]
+				if !ok1 || !ok2 {
+					b.reject("relation", doc.DocID, relation.RelationID, relation.RelationType, "missing or rejected head/tail entity")
+					continue
+				}
+				b.addRelation(headKey, relation.RelationType, tailKey, model.DiaKGRelationID(doc.DocID, relation.RelationID), source)
+			}
+		}
+	}
+
+	return b
+}
+
+// newTripleBatch 将三元组转换为待写入的行，Source 为空的三元组使用 defaultSource
+func newTripleBatch(triples []model.KGTriple, defaultSource string) *graphBatch {
+	b := newGraphBatch()
+	for i, t := range triples {
+		if err := t.Validate(); err != nil {
+			b.reject("triple", "", fmt.Sprintf("#%d %s-%s->%s", i+1, t.Head, t.Relation, t.Tail), t.Relation, err.Error())
+			continue
+		}
+		source := t.Source
+		if source == "" {
+			source = defaultSource
+		}
+		headKey := b.addEntity(t.Head, t.HeadType, source)
+		tailKey := b.addEntity(t.Tail, t.TailType, source)
+		b.addRelation(headKey, t.Relation, tailKey, model.TripleRelationID(source, headKey, t.Relation, tailKey), source)
+	}
+	return b
+}
+
+// addEntity 添加规范实体行并返回其键，同一批次内重复的实体和来源只写入一次
+func (b *graphBatch) addEntity(name, entityType, source string) string {
+	key := model.CanonicalEntityKey(name, entityType)
+	if !b.seen[key+"@"+source] {
+		b.seen[key+"@"+source] = true
+		b.entities = append(b.entities, map[string]any{
+			"key":             key,
+			"name":            strings.TrimSpace(name),
+			"normalized_name": model.NormalizeEntityName(name),
+			"type":            entityType,
+			"source":          source,
+		})
+	}
+	return key
+}
+
+func (b *graphBatch) addRelation(headKey, relationType, tailKey, relationID, source string) {
+	b.relations[relationType] = append(b.relations[relationType], map[string]any{
+		"head_key":    headKey,
+		"tail_key":    tailKey,
+		"relation_id": relationID,
+		"source":      source,
+	})
+}
+
+func (b *graphBatch) reject(kind, docID, id, typ, reason string) {
+	b.rejected = append(b.rejected, rejection{Kind: kind, DocID: docID, ID: id, Type: typ, Reason: reason})
+}
+
+// write 依次写入各类节点和关系，后一步依赖前一步创建的节点
+func (im *importer) write(ctx context.Context, b *graphBatch) error {
+	if err := im.embed(ctx, b); err != nil {
+		return fmt.Errorf("error computing embeddings: %v", err)
+	}
+
+	steps := []struct {
+		name  string
+		query string
+		rows  []map[string]any
+	}{
+		{"documents", `
+			UNWIND $rows AS row
+			MERGE (d:Document {doc_id: row.doc_id})
+			SET d.source = row.source
+		`, b.documents},
+		{"paragraphs", `
+			UNWIND $rows AS row
+			MATCH (d:Document {doc_id: row.doc_id})
+			MERGE (p:Paragraph {key: row.key})
+			SET p.doc_id = row.doc_id,
+				p.paragraph_id = row.paragraph_id,
+				p.text = row.text
+			MERGE (d)-[:CONTAINS_PARAGRAPH]->(p)
+		`, b.paragraphs},
+		{"sentences", `
+			UNWIND $rows AS row
+			MATCH (p:Paragraph {key: row.paragraph_key})
+			MERGE (s:Sentence {key: row.key})
+			SET s.doc_id = row.doc_id,
+				s.sentence_id = row.sentence_id,
+				s.text = row.text
+			FOREACH (_ IN CASE WHEN row.embedding IS NULL THEN [] ELSE [1] END |
+				SET s.embedding = row.embedding, s.embedding_model = row.embedding_model)
+			MERGE (p)-[:CONTAINS_SENTENCE]->(s)
+		`, b.sentences},
+		{"entities", `
+			UNWIND $rows AS row
+			MERGE (e:Entity {key: row.key})
+			ON CREATE SET e.name = row.name,
+				e.normalized_name = row.normalized_name,
+				e.type = row.type
+			FOREACH (_ IN CASE WHEN row.embedding IS NULL THEN [] ELSE [1] END |
+				SET e.embedding = row.embedding, e.embedding_model = row.embedding_model)
+			WITH e, row, coalesce(e.sources, []) AS sources
+			SET e.sources = CASE WHEN row.source IN sources THEN sources ELSE sources + row.source END
+		`, b.entities},
+		// 句子到规范实体的关系记录一次实体标注
+		{"mentions", `
+			UNWIND $rows AS row
+			MATCH (s:Sentence {key: row.sentence_key})
+			MATCH (e:Entity {key: row.key})
+			MERGE (s)-[m:CONTAINS_ENTITY {entity_id: row.entity_id}]->(e)
+			SET m.start_idx = row.start_idx,
+				m.end_idx = row.end_idx
+		`, b.mentions},
+	}
+	for _, step := range steps {
+		if err := im.run(ctx, step.query, step.rows); err != nil {
+			return fmt.Errorf("error saving %s: %v", step.name, err)
+		}
+	}
+
+	relationTypes := make([]string, 0, len(b.relations))
+	for t := range b.relations {
+		relationTypes = append(relationTypes, t)
+	}
+	sort.Strings(relationTypes)
+
+	// weight 为标注该关系的次数，以 relation_id 记录每次标注，重复导入不会重复计数
+	for _, t := range relationTypes {
+		// 关系类型会拼接进查询语句，只允许标注体系中的类型，防止注入
+		if !model.IsDiaKGRelationType(t) {
+			return fmt.Errorf("invalid relation type %q", t)
+		}
+		query := fmt.Sprintf(`
+			UNWIND $rows AS row
+			MATCH (e1:Entity {key: row.head_key})
+			MATCH (e2:Entity {key: row.tail_key})
+			MERGE (e1)-[r:%s]->(e2)
+			WITH r, row, coalesce(r.relation_ids, []) AS ids, coalesce(r.sources, []) AS sources
+			SET r.relation_ids = CASE WHEN row.relation_id IN ids THEN ids ELSE ids + row.relation_id END,
+				r.sources = CASE WHEN row.source IN sources THEN sources ELSE sources + row.source END
+			SET r.weight = size(r.relation_ids)
+		`, t)
+		if err := im.run(ctx, query, b.relations[t]); err != nil {
+			return fmt.Errorf("error saving %s relations: %v", t, err)
+		}
+	}
+
+	return nil
+}
+
+// run 按批次大小切分 rows，每批在独立的事务中执行
+func (im *importer) run(ctx context.Context, query string, rows []map[string]any) error {
+	session := im.session(ctx)
+	defer session.Close(ctx)
+
+	for start := 0; start < len(rows); start += im.batchSize {
+		batch := rows[start:min(start+im.batchSize, len(rows))]
+		_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+			_, err := tx.Run(ctx, query, map[string]any{"rows": batch})
+			return nil, err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
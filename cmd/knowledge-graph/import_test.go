@@ -0,0 +1,104 @@
+package main
+
+import (
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// sharedIDDocument 构造一个段落、句子和实体 ID 与其他文档相同的文档
+func sharedIDDocument(docID, drug string) model.DiaKGDocument {
+	text := drug + "用于2型糖尿病。"
+	return model.DiaKGDocument{
+		DocID: docID,
+		Paragraphs: []model.DiaKGParagraph{{
+			ParagraphID: "0",
+			Paragraph:   text,
+			Sentences: []model.DiaKGSentence{{
+				SentenceID: "0",
+				Sentence:   text,
+				Entities: []model.DiaKGEntity{
+					{EntityID: "T0", Entity: drug, EntityType: "Drug"},
+					{EntityID: "T1", Entity: "2型糖尿病", EntityType: "Disease"},
+				},
+				Relations: []model.DiaKGRelation{
+					{RelationID: "R0", RelationType: "Drug_Disease", HeadEntityID: "T0", TailEntityID: "T1"},
+				},
+			}},
+		}},
+	}
+}
+
+func TestNewDocumentBatchScopesPassagesByDocument(t *testing.T) {
+	docs := []model.DiaKGDocument{
+		sharedIDDocument("1", "二甲双胍"),
+		sharedIDDocument("2", "胰岛素"),
+	}
+
+	var paragraphs, sentences, mentions []string
+	for _, doc := range docs {
+		b := newDocumentBatch(doc, model.DiaKGSource)
+		for _, row := range b.paragraphs {
+			paragraphs = append(paragraphs, fmt.Sprintf("%v %v/%v %v", row["key"], row["doc_id"], row["paragraph_id"], row["text"]))
+		}
+		for _, row := range b.sentences {
+			sentences = append(sentences, fmt.Sprintf("%v in %v %v/%v", row["key"], row["paragraph_key"], row["doc_id"], row["sentence_id"]))
+		}
+		for _, row := range b.mentions {
+			mentions = append(mentions, fmt.Sprintf("%v %v->%v", row["sentence_key"], row["entity_id"], row["key"]))
+		}
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{
+			"paragraphs",
+			paragraphs,
+			[]string{"1/0 1/0 二甲双胍用于2型糖尿病。", "2/0 2/0 胰岛素用于2型糖尿病。"},
+		},
+		{
+			"sentences",
+			sentences,
+			[]string{"1/0 in 1/0 1/0", "2/0 in 2/0 2/0"},
+		},
+		{
+			"mentions",
+			mentions,
+			[]string{
+				"1/0 T0->Drug:二甲双胍", "1/0 T1->Disease:2型糖尿病",
+				"2/0 T0->Drug:胰岛素", "2/0 T1->Disease:2型糖尿病",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !slices.Equal(tt.got, tt.want) {
+				t.Errorf("rows = %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildDocumentsSharedPassageIDs(t *testing.T) {
+	rows := []documentSentence{
+		{DocID: "1", ParagraphID: "0", Paragraph: "二甲双胍用于2型糖尿病。", SentenceID: "0", Sentence: "二甲双胍用于2型糖尿病。"},
+		{DocID: "2", ParagraphID: "0", Paragraph: "胰岛素用于2型糖尿病。", SentenceID: "0", Sentence: "胰岛素用于2型糖尿病。"},
+	}
+
+	var got []string
+	for _, doc := range buildDocuments(rows, nil) {
+		for _, para := range doc.Paragraphs {
+			for _, sentence := range para.Sentences {
+				got = append(got, doc.DocID+"/"+para.ParagraphID+"/"+sentence.SentenceID+" "+sentence.Sentence)
+			}
+		}
+	}
+	want := []string{"1/0/0 二甲双胍用于2型糖尿病。", "2/0/0 胰岛素用于2型糖尿病。"}
+	if !slices.Equal(got, want) {
+		t.Errorf("sentences = %q, want %q", got, want)
+	}
+}
@@ -3,277 +3,415 @@ package main
 import (
 	"context"
 	"diabetes-care-mcp-server/config"
-	"diabetes-care-mcp-server/tools"
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/embedding"
+	"diabetes-care-mcp-server/model"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
+	"slices"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 const createIndexTimeout = 10
 
-type Entity struct {
-	EntityID   string `json:"entity_id"`
-	Entity     string `json:"entity"`
-	EntityType string `json:"entity_type"`
-	StartIdx   int    `json:"start_idx"`
-	EndIdx     int    `json:"end_idx"`
-}
+const defaultInput = "resource/diakg/*.json"
 
-type Relation struct {
-	RelationType string `json:"relation_type"`
-	RelationID   string `json:"relation_id"`
-	HeadEntityID string `json:"head_entity_id"`
-	TailEntityID string `json:"tail_entity_id"`
-}
+func main() {
+	// 子命令：import（默认）导入数据集，export 导出图谱
+	args := os.Args[1:]
+	command := "import"
+	if len(args) > 0 && (args[0] == "import" || args[0] == "export") {
+		command, args = args[0], args[1:]
+	}
 
-type Sentence struct {
-	SentenceID string     `json:"sentence_id"`
-	Sentence   string     `json:"sentence"`
-	StartIdx   int        `json:"start_idx"`
-	EndIdx     int        `json:"end_idx"`
-	Entities   []Entity   `json:"entities"`
-	Relations  []Relation `json:"relations"`
+	if command == "export" {
+		runExport(args)
+		return
+	}
+	runImport(args)
 }
 
-type Paragraph struct {
-	ParagraphID string     `json:"paragraph_id"`
-	Paragraph   string     `json:"paragraph"`
-	Sentences   []Sentence `json:"sentences"`
-}
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configPath := fs.String("config", "config.yaml", "path to config file")
+	inputPattern := fs.String("input", "", "glob of knowledge graph files to import (default db.knowledge_graph_data or "+defaultInput+")")
+	format := fs.String("format", "auto", "input format: auto (by file extension), diakg, csv, jsonl or turtle")
+	source := fs.String("source", "", "dataset name for nodes and relations without an explicit source (default: diakg for DiaKG files, file name for triple files)")
+	database := fs.String("database", "", "target Neo4j database (default: server default database)")
+	dryRun := fs.Bool("dry-run", false, "parse and validate the input, print a statistics report and exit without writing")
+	wipe := fs.Bool("wipe", false, "delete the existing graph before importing")
+	batchSize := fs.Int("batch-size", defaultBatchSize, "number of rows written per transaction")
+	checkpointPath := fs.String("checkpoint", "kg-import.checkpoint.json", "file recording imported files for resuming; empty disables it")
+	restart := fs.Bool("restart", false, "ignore the checkpoint and import all files again")
+	aliasesPath := fs.String("aliases", "", "alias dictionary CSV (name, type, alias) attached to entities after import, replacing existing aliases (default db.knowledge_graph_aliases)")
+	embed := fs.Bool("embed", true, "compute entity and sentence vectors for semantic search with model.embedding_model, or local n-gram hashing when unset")
+	rejectedPath := fs.String("rejected", "kg-import.rejected.jsonl", "file listing entities and relations rejected by schema validation; empty disables it")
+	fs.Parse(args)
+
+	if *batchSize < 1 {
+		slog.Error("Invalid batch size", "batch_size", *batchSize)
+		os.Exit(1)
+	}
+	if *format != formatAuto && !slices.Contains(dao.KGFormats, *format) {
+		slog.Error("Invalid input format", "format", *format)
+		os.Exit(1)
+	}
+	in := input{format: *format, source: *source}
 
-type Document struct {
-	DocID      string      `json:"doc_id"`
-	Paragraphs []Paragraph `json:"paragraphs"`
-}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		// 校验数据集不需要连接数据库，允许没有配置文件
+		if !*dryRun {
+			slog.Error("Failed to load config", "err", err)
+			os.Exit(1)
+		}
+		cfg = &config.Config{}
+	}
 
-func main() {
-	ctx := context.Background()
-	dsn := fmt.Sprintf("neo4j://%s:%s", config.Cfg.DB.Neo4j.Host, config.Cfg.DB.Neo4j.Port)
+	pattern := *inputPattern
+	if pattern == "" {
+		pattern = cfg.DB.KnowledgeGraphData
+	}
+	if pattern == "" {
+		pattern = defaultInput
+	}
 
-	driver, err := neo4j.NewDriverWithContext(
-		dsn,
-		neo4j.BasicAuth(config.Cfg.DB.Neo4j.Username, config.Cfg.DB.Neo4j.Password, ""),
-	)
+	// 读取数据集，快照目录中的 triples.csv 随文档一并导入
+	files, err := dao.GlobKGFiles(pattern)
 	if err != nil {
-		slog.Error("Failed to create Neo4j driver", "err", err)
+		slog.Error("Invalid input pattern", "input", pattern, "err", err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		slog.Error("No input files found", "input", pattern)
+		os.Exit(1)
+	}
+
+	if *aliasesPath == "" {
+		*aliasesPath = cfg.DB.KnowledgeGraphAliases
+	}
+
+	if *dryRun {
+		stats := validateFiles(files, in)
+		if *aliasesPath != "" {
+			stats.validateAliases(*aliasesPath)
+		}
+		stats.print(os.Stdout)
+		if stats.issueCount() > 0 {
+			os.Exit(1)
+		}
 		return
 	}
-	defer driver.Close(ctx)
 
-	err = driver.VerifyConnectivity(ctx)
+	ctx := context.Background()
+	driver, err := connect(ctx, cfg)
 	if err != nil {
 		slog.Error("Failed to connect to Neo4j", "err", err)
-		return
+		os.Exit(1)
 	}
+	defer driver.Close(ctx)
 
-	// 读取数据集
-	files, err := filepath.Glob("resource/diakg/*.json")
+	cp, err := loadCheckpoint(*checkpointPath)
 	if err != nil {
-		slog.Error("Error reading directory", "err", err)
-		return
+		slog.Error("Failed to load checkpoint", "err", err)
+		os.Exit(1)
 	}
 
-	for _, file := range files {
-		slog.Info("Processing file", "file", file)
-		err = processFile(ctx, driver, file)
+	im := &importer{driver: driver, database: *database, batchSize: *batchSize}
+	if *embed {
+		if im.embedder, err = embedding.New(cfg); err != nil {
+			slog.Error("Failed to create embedder", "err", err)
+			os.Exit(1)
+		}
+		// 已记入检查点的文件不会重新写入，更换向量模型后需使用 --restart
+		slog.Info("Computing embeddings", "model", im.embedder.Model())
+	}
+
+	// 旧版导入的实体、段落和句子没有 key，MERGE 无法与之合并，继续导入会产生重复节点，需先清空图谱
+	if !*wipe {
+		legacy, err := im.legacyNodeCount(ctx)
 		if err != nil {
-			slog.Error("Error processing file",
-				"file", file,
-				"err", err,
+			slog.Error("Failed to check existing knowledge graph", "err", err)
+			os.Exit(1)
+		}
+		if legacy > 0 {
+			slog.Error("Knowledge graph contains nodes from an older import without key, re-run with --wipe to rebuild it",
+				"nodes", legacy,
 			)
-			return
+			os.Exit(1)
+		}
+	}
+
+	if *wipe {
+		slog.Info("Wiping existing knowledge graph", "database", *database)
+		if err := im.wipe(ctx); err != nil {
+			slog.Error("Failed to wipe knowledge graph", "err", err)
+			os.Exit(1)
+		}
+	}
+	// 清空图谱后检查点记录的文件也需要重新导入
+	if *restart || *wipe {
+		if err := cp.reset(); err != nil {
+			slog.Error("Failed to reset checkpoint", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	// 先创建唯一性约束，MERGE 才能走索引
+	if err := im.createConstraints(ctx); err != nil {
+		slog.Error("Failed to create constraints", "err", err)
+		os.Exit(1)
+	}
+
+	summary := importFiles(ctx, im, cp, files, in)
+	summary.log()
+	if err := summary.writeRejected(*rejectedPath); err != nil {
+		slog.Error("Failed to write rejected report", "err", err)
+	}
+
+	if *aliasesPath != "" {
+		if err := applyAliases(ctx, im, *aliasesPath); err != nil {
+			slog.Error("Failed to import aliases", "path", *aliasesPath, "err", err)
+			os.Exit(1)
 		}
 	}
 
 	// 检查全文索引，若不存在进行创建
-	if err := checkFullTextIndex(ctx, driver); err != nil {
+	if err := im.checkFullTextIndex(ctx); err != nil {
 		slog.Error("Failed to check fulltext index", "err", err)
-		return
+		os.Exit(1)
+	}
+	if err := im.checkPassageIndex(ctx); err != nil {
+		slog.Error("Failed to check passage index", "err", err)
+		os.Exit(1)
+	}
+
+	if im.embedder != nil {
+		if err := im.checkVectorIndexes(ctx); err != nil {
+			slog.Error("Failed to check vector indexes", "err", err)
+			os.Exit(1)
+		}
 	}
 
+	if len(summary.failed) > 0 {
+		os.Exit(1)
+	}
 	slog.Info("Created knowledge graph successfully")
 }
 
-func processFile(ctx context.Context, driver neo4j.DriverWithContext, filePath string) error {
-	data, err := os.ReadFile(filePath)
+func connect(ctx context.Context, cfg *config.Config) (neo4j.DriverWithContext, error) {
+	dsn := fmt.Sprintf("neo4j://%s:%s", cfg.DB.Neo4j.Host, cfg.DB.Neo4j.Port)
+
+	driver, err := neo4j.NewDriverWithContext(
+		dsn,
+		neo4j.BasicAuth(cfg.DB.Neo4j.Username, cfg.DB.Neo4j.Password, ""),
+	)
 	if err != nil {
-		return fmt.Errorf("error reading file %s: %v", filePath, err)
+		return nil, fmt.Errorf("failed to create Neo4j driver: %v", err)
 	}
 
-	var doc Document
-	if err := json.Unmarshal(data, &doc); err != nil {
-		return fmt.Errorf("error unmarshaling JSON: %v", err)
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		driver.Close(ctx)
+		return nil, err
 	}
+	return driver, nil
+}
+
+// importSummary 各文件的导入结果
+type importSummary struct {
+	imported []string
+	skipped  []string
+	failed   []fileError
+	rejected []rejection
+}
 
-	return saveDocument(ctx, driver, doc)
+type fileError struct {
+	file string
+	err  error
 }
 
-func saveDocument(ctx context.Context, driver neo4j.DriverWithContext, doc Document) error {
-	session := driver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeWrite,
-	})
-	defer session.Close(ctx)
+// importFiles 逐个导入文件，单个文件失败不影响其余文件，成功的文件记入检查点
+func importFiles(ctx context.Context, im *importer, cp *checkpoint, files []string, in input) *importSummary {
+	summary := &importSummary{}
 
-	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
-		if err := saveDocumentNode(tx, doc); err != nil {
-			return nil, fmt.Errorf("error saving document node %s: %v", doc.DocID, err)
+	for _, file := range files {
+		digest, err := fileDigest(file)
+		if err != nil {
+			summary.failed = append(summary.failed, fileError{file, err})
+			continue
 		}
-
-		for _, para := range doc.Paragraphs {
-			if err := saveParagraphNode(tx, doc.DocID, para); err != nil {
-				return nil, fmt.Errorf("error saving paragraph node %s: %v", para.ParagraphID, err)
-			}
+		if cp.done(file, digest) {
+			summary.skipped = append(summary.skipped, file)
+			continue
 		}
 
-		return nil, nil
-	})
+		slog.Info("Processing file", "file", file)
+		rejected, err := processFile(ctx, im, file, in)
+		for i := range rejected {
+			rejected[i].File = file
+		}
+		if len(rejected) > 0 {
+			slog.Warn("Rejected entities and relations not in DiaKG schema",
+				"file", file,
+				"count", len(rejected),
+			)
+			summary.rejected = append(summary.rejected, rejected...)
+		}
+		if err != nil {
+			slog.Error("Error processing file",
+				"file", file,
+				"err", err,
+			)
+			summary.failed = append(summary.failed, fileError{file, err})
+			continue
+		}
+		if err := cp.mark(file, digest); err != nil {
+			slog.Warn("Failed to update checkpoint",
+				"file", file,
+				"err", err,
+			)
+		}
+		summary.imported = append(summary.imported, file)
+	}
 
-	return err
+	return summary
 }
 
-func saveDocumentNode(tx neo4j.ManagedTransaction, doc Document) error {
-	query := `
-		MERGE (d:Document {doc_id: $doc_id})
-		RETURN d
-    `
-	_, err := tx.Run(context.Background(), query, map[string]any{
-		"doc_id": doc.DocID,
-	})
-	return err
+func (s *importSummary) log() {
+	slog.Info("Import finished",
+		"imported", len(s.imported),
+		"skipped", len(s.skipped),
+		"failed", len(s.failed),
+		"rejected", len(s.rejected),
+	)
+	for _, f := range s.failed {
+		slog.Error("Failed to import file",
+			"file", f.file,
+			"err", f.err,
+		)
+	}
 }
 
-func saveParagraphNode(tx neo4j.ManagedTransaction, docID string, para Paragraph) error {
-	query := `
-		MATCH (d:Document {doc_id: $doc_id})
-		MERGE (p:Paragraph {paragraph_id: $para_id})
-		SET p.text = $text
-		MERGE (d)-[:CONTAINS_PARAGRAPH]->(p)
-    `
-	if _, err := tx.Run(context.Background(), query, map[string]any{
-		"doc_id":  docID,
-		"para_id": para.ParagraphID,
-		"text":    para.Paragraph,
-	}); err != nil {
-		return fmt.Errorf("error creating paragraph node: %v", err)
-	}
-
-	for _, sentence := range para.Sentences {
-		if err := saveSentenceNode(tx, para.ParagraphID, sentence); err != nil {
-			return fmt.Errorf("error saving sentence node %s: %v", sentence.SentenceID, err)
-		}
+// writeRejected 将被拒绝的实体和关系逐行写入 JSON Lines 报告，便于修正数据后重新导入
+func (s *importSummary) writeRejected(path string) error {
+	if path == "" || len(s.rejected) == 0 {
+		return nil
 	}
 
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, r := range s.rejected {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	slog.Info("Wrote rejected report", "path", path)
 	return nil
 }
 
-func saveSentenceNode(tx neo4j.ManagedTransaction, paraID string, sentence Sentence) error {
-	query := `
-		MATCH (p:Paragraph {paragraph_id: $para_id})
-		MERGE (s:Sentence {sentence_id: $sentence_id})
-		SET s.text = $text
-		MERGE (p)-[:CONTAINS_SENTENCE]->(s)
-    `
-	if _, err := tx.Run(context.Background(), query, map[string]any{
-		"para_id":     paraID,
-		"sentence_id": sentence.SentenceID,
-		"text":        sentence.Sentence,
-	}); err != nil {
-		return fmt.Errorf("error creating sentence node: %v", err)
-	}
-
-	for _, entity := range sentence.Entities {
-		if err := saveEntityNode(tx, sentence.SentenceID, entity); err != nil {
-			return fmt.Errorf("error saving entity node %s: %v", entity.EntityID, err)
-		}
+func processFile(ctx context.Context, im *importer, filePath string, in input) ([]rejection, error) {
+	format, err := in.formatOf(filePath)
+	if err != nil {
+		return nil, err
 	}
+	source := in.sourceOf(filePath, format)
 
-	for _, relation := range sentence.Relations {
-		if err := saveRelation(tx, relation); err != nil {
-			return fmt.Errorf("error saving relation %s: %v", relation.RelationID, err)
+	if format == dao.KGFormatDiaKG {
+		doc, err := dao.ReadDiaKGDocument(filePath)
+		if err != nil {
+			return nil, err
 		}
+		return im.importDocument(ctx, *doc, source)
 	}
 
-	return nil
+	triples, err := dao.ReadTriples(filePath, format)
+	if err != nil {
+		return nil, err
+	}
+	return im.importTriples(ctx, triples, source)
 }
 
-func saveEntityNode(tx neo4j.ManagedTransaction, sentenceID string, entity Entity) error {
-	query := `
-		MATCH (s:Sentence {sentence_id: $sentence_id})
-		MERGE (e:Entity {entity_id: $entity_id})
-		SET e.name = $name,
-			e.type = $type
-		MERGE (s)-[:CONTAINS_ENTITY]->(e)
-    `
-	_, err := tx.Run(context.Background(), query, map[string]any{
-		"sentence_id": sentenceID,
-		"entity_id":   entity.EntityID,
-		"name":        entity.Entity,
-		"type":        entity.EntityType,
-	})
-	if err != nil {
-		return err
+const formatAuto = "auto"
+
+// input 输入文件的格式和来源设置
+type input struct {
+	format string
+	source string
+}
+
+// formatOf 返回文件的数据格式，未指定格式时按扩展名识别
+func (in input) formatOf(file string) (string, error) {
+	if in.format != formatAuto {
+		return in.format, nil
 	}
-	return nil
+	if format := dao.DetectKGFormat(file); format != "" {
+		return format, nil
+	}
+	return "", fmt.Errorf("cannot detect format of %s, use --format", file)
 }
 
-func saveRelation(tx neo4j.ManagedTransaction, relation Relation) error {
-	query := fmt.Sprintf(`
-		MATCH (e1:Entity {entity_id: $head_id})
-		MATCH (e2:Entity {entity_id: $tail_id})
-		MERGE (e1)-[r:%s]->(e2)
-		SET r.relation_id = $relation_id
-	`, relation.RelationType)
-
-	_, err := tx.Run(context.Background(), query, map[string]interface{}{
-		"head_id":     relation.HeadEntityID,
-		"tail_id":     relation.TailEntityID,
-		"relation_id": relation.RelationID,
-	})
-	if err != nil {
-		return err
+// sourceOf 返回文件中未注明来源的节点和关系使用的数据集名称
+func (in input) sourceOf(file, format string) string {
+	if in.source != "" {
+		return in.source
 	}
-	return nil
+	if format == dao.KGFormatDiaKG {
+		return model.DiaKGSource
+	}
+	return dao.TripleSource(file)
 }
 
-func checkFullTextIndex(ctx context.Context, driver neo4j.DriverWithContext) error {
-	s := driver.NewSession(ctx, neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeWrite,
-	})
+func (im *importer) checkFullTextIndex(ctx context.Context) error {
+	s := im.session(ctx)
 	defer s.Close(ctx)
 
 	check := `
 		SHOW FULLTEXT INDEXES
-		YIELD name
+		YIELD name, properties
 		WHERE name = $name
-		RETURN count(*) AS count
+		RETURN properties
 	`
-	res, err := s.Run(ctx, check, map[string]any{"name": tools.Neo4jFulltextIndexName})
+	res, err := s.Run(ctx, check, map[string]any{"name": dao.Neo4jFulltextIndexName})
 	if err != nil {
 		return fmt.Errorf("failed to list fulltext indexes: %w", err)
 	}
 
-	idxExists := false
+	idxExists, idxCurrent := false, false
 	if res.Next(ctx) {
-		if cnt, ok := res.Record().Get("count"); ok {
-			if c, ok2 := cnt.(int64); ok2 && c > 0 {
-				idxExists = true
+		idxExists = true
+		if props, ok := res.Record().Get("properties"); ok {
+			if list, ok := props.([]any); ok {
+				idxCurrent = slices.Contains(list, any(aliasTextProperty))
 			}
 		}
 	}
 	if err := res.Err(); err != nil {
 		return fmt.Errorf("failed to read index list: %v", err)
 	}
-	if idxExists {
-		slog.Info(fmt.Sprintf("%s index already exists", tools.Neo4jFulltextIndexName))
+	if idxCurrent {
+		slog.Info(fmt.Sprintf("%s index already exists", dao.Neo4jFulltextIndexName))
 		return nil
 	}
 
-	// 在 Entity 节点的 name 属性上建立全文索引
-	create := fmt.Sprintf(`CREATE FULLTEXT INDEX %s FOR (n:Entity) ON EACH [n.name]`, tools.Neo4jFulltextIndexName)
+	// 旧版本的索引只包含 name，重建以覆盖别名
+	if idxExists {
+		if _, err := s.Run(ctx, fmt.Sprintf(`DROP INDEX %s`, dao.Neo4jFulltextIndexName), nil); err != nil {
+			return fmt.Errorf("failed to drop outdated fulltext index: %v", err)
+		}
+		slog.Info(fmt.Sprintf("Dropped outdated index: %s", dao.Neo4jFulltextIndexName))
+	}
+
+	// 在 Entity 节点的名称和别名上建立全文索引
+	create := fmt.Sprintf(`CREATE FULLTEXT INDEX %s FOR (n:Entity) ON EACH [n.name, n.%s]`, dao.Neo4jFulltextIndexName, aliasTextProperty)
 
 	_, err = s.Run(ctx, create, nil)
 	if err != nil {
@@ -287,7 +425,30 @@ func checkFullTextIndex(ctx context.Context, driver neo4j.DriverWithContext) err
 		return fmt.Errorf("failed to wait for index creation: %v", err)
 	}
 
-	slog.Info(fmt.Sprintf("Successfully created index: %s", tools.Neo4jFulltextIndexName))
+	slog.Info(fmt.Sprintf("Successfully created index: %s", dao.Neo4jFulltextIndexName))
+
+	return nil
+}
+
+// checkPassageIndex 在句子和段落原文上建立全文索引，使用 cjk 分析器将中文切分为二元组
+func (im *importer) checkPassageIndex(ctx context.Context) error {
+	s := im.session(ctx)
+	defer s.Close(ctx)
+
+	create := fmt.Sprintf(
+		"CREATE FULLTEXT INDEX %s IF NOT EXISTS FOR (n:Sentence|Paragraph) ON EACH [n.text] "+
+			"OPTIONS {indexConfig: {`fulltext.analyzer`: 'cjk'}}",
+		dao.Neo4jPassageIndexName,
+	)
+	if _, err := s.Run(ctx, create, nil); err != nil {
+		return fmt.Errorf("failed to create passage index: %v", err)
+	}
+
+	wait := fmt.Sprintf(`CALL db.awaitIndexes(%d)`, createIndexTimeout)
+	if _, err := s.Run(ctx, wait, nil); err != nil {
+		return fmt.Errorf("failed to wait for index creation: %v", err)
+	}
 
+	slog.Info(fmt.Sprintf("%s index is ready", dao.Neo4jPassageIndexName))
 	return nil
 }
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// 每类问题在报告中列出的示例条数
+const maxIssueExamples = 20
+
+const (
+	issueParseError          = "parse_error"
+	issueIncompleteTriple    = "incomplete_triple"
+	issueUnknownEntityType   = "unknown_entity_type"
+	issueUnknownRelationType = "unknown_relation_type"
+	issueDanglingHead        = "dangling_head_entity"
+	issueDanglingTail        = "dangling_tail_entity"
+	issueSentenceOffset      = "sentence_offset_mismatch"
+	issueEntityOffset        = "entity_offset_mismatch"
+	issueUnknownAliasEntity  = "unknown_alias_entity"
+)
+
+type validationIssue struct {
+	file   string
+	id     string
+	detail string
+}
+
+// datasetStats 数据集的统计信息及校验发现的问题
+type datasetStats struct {
+	files      int
+	documents  int
+	paragraphs int
+	sentences  int
+	mentions   int
+	triples    int
+	relations  int
+	aliases    int
+	// entities 规范实体键，用于统计合并后的实体数
+	entities      map[string]bool
+	entityTypes   map[string]int
+	relationTypes map[string]int
+	issues        map[string][]validationIssue
+}
+
+func newDatasetStats() *datasetStats {
+	return &datasetStats{
+		entities:      make(map[string]bool),
+		entityTypes:   make(map[string]int),
+		relationTypes: make(map[string]int),
+		issues:        make(map[string][]validationIssue),
+	}
+}
+
+// validateFiles 解析并校验所有文件，不写入数据库
+func validateFiles(files []string, in input) *datasetStats {
+	stats := newDatasetStats()
+	for _, file := range files {
+		stats.files++
+		format, err := in.formatOf(file)
+		if err != nil {
+			stats.issue(issueParseError, file, "", err.Error())
+			continue
+		}
+
+		if format == dao.KGFormatDiaKG {
+			doc, err := dao.ReadDiaKGDocument(file)
+			if err != nil {
+				stats.issue(issueParseError, file, "", err.Error())
+				continue
+			}
+			stats.validateDocument(file, doc)
+			continue
+		}
+
+		triples, err := dao.ReadTriples(file, format)
+		if err != nil {
+			stats.issue(issueParseError, file, "", err.Error())
+			continue
+		}
+		stats.validateTriples(file, triples)
+	}
+	return stats
+}
+
+func (s *datasetStats) validateDocument(file string, doc *model.DiaKGDocument) {
+	s.documents++
+
+	entityIDs := make(map[string]bool)
+	for _, para := range doc.Paragraphs {
+		for _, sentence := range para.Sentences {
+			for _, entity := range sentence.Entities {
+				entityIDs[entity.EntityID] = true
+			}
+		}
+	}
+
+	for _, para := range doc.Paragraphs {
+		s.paragraphs++
+		paragraph := []rune(para.Paragraph)
+
+		for _, sentence := range para.Sentences {
+			s.sentences++
+			text := []rune(sentence.Sentence)
+
+			if !spanMatches(paragraph, sentence.StartIdx, sentence.EndIdx, sentence.Sentence) {
+				s.issue(issueSentenceOffset, file, sentence.SentenceID,
+					fmt.Sprintf("[%d:%d] does not match sentence text", sentence.StartIdx, sentence.EndIdx))
+			}
+
+			for _, entity := range sentence.Entities {
+				s.mentions++
+				s.entityTypes[entity.EntityType]++
+				s.entities[model.CanonicalEntityKey(entity.Entity, entity.EntityType)] = true
+
+				if !model.IsDiaKGEntityType(entity.EntityType) {
+					s.issue(issueUnknownEntityType, file, doc.DocID+"/"+entity.EntityID, entity.EntityType)
+				}
+				// 偏移量可能相对句子，也可能相对段落，二者均不匹配时才视为错误
+				if !spanMatches(text, entity.StartIdx, entity.EndIdx, entity.Entity) &&
+					!spanMatches(paragraph, entity.StartIdx, entity.EndIdx, entity.Entity) {
+					s.issue(issueEntityOffset, file, doc.DocID+"/"+entity.EntityID,
+						fmt.Sprintf("%q at [%d:%d] in sentence %s", entity.Entity, entity.StartIdx, entity.EndIdx, sentence.SentenceID))
+				}
+			}
+
+			for _, relation := range sentence.Relations {
+				s.relations++
+				s.relationTypes[relation.RelationType]++
+				id := doc.DocID + "/" + relation.RelationID
+
+				if !model.IsDiaKGRelationType(relation.RelationType) {
+					s.issue(issueUnknownRelationType, file, id, relation.RelationType)
+				}
+				if !entityIDs[relation.HeadEntityID] {
+					s.issue(issueDanglingHead, file, id, relation.HeadEntityID)
+				}
+				if !entityIDs[relation.TailEntityID] {
+					s.issue(issueDanglingTail, file, id, relation.TailEntityID)
+				}
+			}
+		}
+	}
+}
+
+func (s *datasetStats) validateTriples(file string, triples []model.KGTriple) {
+	for i, t := range triples {
+		s.triples++
+		s.relations++
+		s.relationTypes[t.Relation]++
+		id := fmt.Sprintf("#%d", i+1)
+
+		if t.Head == "" || t.HeadType == "" || t.Relation == "" || t.Tail == "" || t.TailType == "" {
+			s.issue(issueIncompleteTriple, file, id, fmt.Sprintf("%s(%s) -%s-> %s(%s)", t.Head, t.HeadType, t.Relation, t.Tail, t.TailType))
+			continue
+		}
+		for _, e := range []struct{ name, typ string }{{t.Head, t.HeadType}, {t.Tail, t.TailType}} {
+			s.entityTypes[e.typ]++
+			s.entities[model.CanonicalEntityKey(e.name, e.typ)] = true
+			if !model.IsDiaKGEntityType(e.typ) {
+				s.issue(issueUnknownEntityType, file, id, e.typ)
+			}
+		}
+		if !model.IsDiaKGRelationType(t.Relation) {
+			s.issue(issueUnknownRelationType, file, id, t.Relation)
+		}
+	}
+}
+
+// validateAliases 校验别名词典，别名指向的实体需出现在数据集中
+func (s *datasetStats) validateAliases(path string) {
+	aliases, err := dao.ReadAliases(path)
+	if err != nil {
+		s.issue(issueParseError, path, "", err.Error())
+		return
+	}
+
+	names := make(map[string]bool)
+	for key := range s.entities {
+		_, name, _ := strings.Cut(key, ":")
+		names[name] = true
+	}
+	for i, a := range aliases {
+		s.aliases++
+		found := names[model.NormalizeEntityName(a.Name)]
+		if a.Type != "" {
+			found = s.entities[model.CanonicalEntityKey(a.Name, a.Type)]
+		}
+		if !found {
+			s.issue(issueUnknownAliasEntity, path, fmt.Sprintf("#%d", i+1), fmt.Sprintf("%s(%s) -> %s", a.Name, a.Type, a.Alias))
+		}
+	}
+}
+
+func (s *datasetStats) issue(kind, file, id, detail string) {
+	s.issues[kind] = append(s.issues[kind], validationIssue{file: file, id: id, detail: detail})
+}
+
+func (s *datasetStats) issueCount() int {
+	n := 0
+	for _, issues := range s.issues {
+		n += len(issues)
+	}
+	return n
+}
+
+// 判断 text 中 [start, end) 的字符是否与 want 一致，偏移量按字符计
+func spanMatches(text []rune, start, end int, want string) bool {
+	if start < 0 || end > len(text) || start > end {
+		return false
+	}
+	return string(text[start:end]) == want
+}
+
+// print 输出统计报告
+func (s *datasetStats) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Dataset\t")
+	fmt.Fprintf(tw, "  files\t%d\n", s.files)
+	fmt.Fprintf(tw, "  documents\t%d\n", s.documents)
+	fmt.Fprintf(tw, "  paragraphs\t%d\n", s.paragraphs)
+	fmt.Fprintf(tw, "  sentences\t%d\n", s.sentences)
+	fmt.Fprintf(tw, "  entity mentions\t%d\n", s.mentions)
+	fmt.Fprintf(tw, "  triples\t%d\n", s.triples)
+	fmt.Fprintf(tw, "  canonical entities\t%d\n", len(s.entities))
+	fmt.Fprintf(tw, "  relations\t%d\n", s.relations)
+	fmt.Fprintf(tw, "  aliases\t%d\n", s.aliases)
+
+	fmt.Fprintln(tw, "\nEntity types\t")
+	printCounts(tw, s.entityTypes)
+	fmt.Fprintln(tw, "\nRelation types\t")
+	printCounts(tw, s.relationTypes)
+
+	fmt.Fprintln(tw, "\nIssues\t")
+	if len(s.issues) == 0 {
+		fmt.Fprintln(tw, "  none\t")
+	}
+	kinds := make([]string, 0, len(s.issues))
+	for kind := range s.issues {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(tw, "  %s\t%d\n", kind, len(s.issues[kind]))
+	}
+	tw.Flush()
+
+	for _, kind := range kinds {
+		issues := s.issues[kind]
+		fmt.Fprintf(w, "\n%s:\n", kind)
+		for _, issue := range issues[:min(len(issues), maxIssueExamples)] {
+			location := issue.file
+			if issue.id != "" {
+				location += " " + issue.id
+			}
+			fmt.Fprintf(w, "  %s: %s\n", location, issue.detail)
+		}
+		if len(issues) > maxIssueExamples {
+			fmt.Fprintf(w, "  ... and %d more\n", len(issues)-maxIssueExamples)
+		}
+	}
+}
+
+// 按数量降序输出各类型的计数
+func printCounts(w io.Writer, counts map[string]int) {
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	for _, t := range types {
+		fmt.Fprintf(w, "  %s\t%d\n", t, counts[t])
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"diabetes-care-mcp-server/dao"
+	"fmt"
+	"log/slog"
+)
+
+// 向量索引及其覆盖的节点，向量均存放在 embedding 属性中
+var vectorIndexes = []struct{ name, label string }{
+	{dao.Neo4jEntityVectorIndexName, "Entity"},
+	{dao.Neo4jSentenceVectorIndexName, "Sentence"},
+}
+
+// embed 为实体名称和句子计算向量，写入行的 embedding 和 embedding_model 字段；未配置 embedder 时跳过
+func (im *importer) embed(ctx context.Context, b *graphBatch) error {
+	if im.embedder == nil {
+		return nil
+	}
+
+	for _, group := range []struct {
+		rows  []map[string]any
+		field string
+	}{
+		{b.entities, "name"},
+		{b.sentences, "text"},
+	} {
+		if len(group.rows) == 0 {
+			continue
+		}
+		texts := make([]string, len(group.rows))
+		for i, row := range group.rows {
+			texts[i], _ = row[group.field].(string)
+		}
+		vectors, err := im.embedder.Embed(ctx, texts)
+		if err != nil {
+			return err
+		}
+		for i, row := range group.rows {
+			row["embedding"] = vectors[i]
+			row["embedding_model"] = im.embedder.Model()
+		}
+	}
+	return nil
+}
+
+// checkVectorIndexes 按当前向量模型的维度创建向量索引，维度不一致的旧索引会被重建
+func (im *importer) checkVectorIndexes(ctx context.Context) error {
+	probe, err := im.embedder.Embed(ctx, []string{"糖尿病"})
+	if err != nil {
+		return fmt.Errorf("failed to compute embedding dimensions: %v", err)
+	}
+	dimensions := len(probe[0])
+
+	s := im.session(ctx)
+	defer s.Close(ctx)
+
+	for _, idx := range vectorIndexes {
+		check := `
+			SHOW VECTOR INDEXES
+			YIELD name, options
+			WHERE name = $name
+			RETURN options.indexConfig['vector.dimensions'] AS dimensions
+		`
+		res, err := s.Run(ctx, check, map[string]any{"name": idx.name})
+		if err != nil {
+			return fmt.Errorf("failed to list vector indexes: %w", err)
+		}
+		existing := int64(-1)
+		if res.Next(ctx) {
+			existing, _ = res.Record().AsMap()["dimensions"].(int64)
+		}
+		if err := res.Err(); err != nil {
+			return fmt.Errorf("failed to read index list: %v", err)
+		}
+
+		if existing == int64(dimensions) {
+			slog.Info(fmt.Sprintf("%s index already exists", idx.name))
+			continue
+		}
+		if existing >= 0 {
+			if _, err := s.Run(ctx, fmt.Sprintf(`DROP INDEX %s`, idx.name), nil); err != nil {
+				return fmt.Errorf("failed to drop vector index %s: %v", idx.name, err)
+			}
+			slog.Info(fmt.Sprintf("Dropped vector index with different dimensions: %s", idx.name),
+				"dimensions", existing,
+			)
+		}
+
+		create := fmt.Sprintf(
+			"CREATE VECTOR INDEX %s FOR (n:%s) ON (n.embedding) "+
+				"OPTIONS {indexConfig: {`vector.dimensions`: %d, `vector.similarity_function`: 'cosine'}}",
+			idx.name, idx.label, dimensions,
+		)
+		if _, err := s.Run(ctx, create, nil); err != nil {
+			return fmt.Errorf("failed to create vector index %s: %v", idx.name, err)
+		}
+		slog.Info(fmt.Sprintf("Successfully created index: %s", idx.name), "dimensions", dimensions)
+	}
+
+	wait := fmt.Sprintf(`CALL db.awaitIndexes(%d)`, createIndexTimeout)
+	if _, err := s.Run(ctx, wait, nil); err != nil {
+		return fmt.Errorf("failed to wait for index creation: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Config struct {
+	Server struct {
+		Port     string `yaml:"port"`
+		LogLevel string `yaml:"log_level"`
+		// 传输方式：http（默认）、sse、stdio，可被命令行参数覆盖
+		Transport string `yaml:"transport"`
+		// stdio 模式没有请求头，使用该用户身份调用工具
+		StdioUserEmail string `yaml:"stdio_user_email"`
+	}
+	DB struct {
+		// 健康数据存储后端：mysql（默认）、sqlite、memory
+		HealthData string `yaml:"health_data"`
+		// 知识图谱后端：neo4j（默认）、memory
+		KnowledgeGraph string `yaml:"knowledge_graph"`
+		// memory 后端加载的 DiaKG 文件 glob
+		KnowledgeGraphData string `yaml:"knowledge_graph_data"`
+		// 实体别名词典 CSV，由 memory 后端加载，或由导入工具写入 Neo4j
+		KnowledgeGraphAliases string   `yaml:"knowledge_graph_aliases"`
+		Neo4j                 DBConfig `yaml:"neo4j"`
+		MySQL                 DBConfig `yaml:"mysql"`
+		SQLite                struct {
+			Path string `yaml:"path"`
+		} `yaml:"sqlite"`
+	} `yaml:"db"`
+	Model struct {
+		APIKey string `yaml:"api_key"`
+		// 兼容 OpenAI 接口的服务地址，默认 https://api.openai.com/v1
+		BaseURL string `yaml:"base_url"`
+		// 向量模型名称，为空时使用本地 n-gram 哈希向量
+		EmbeddingModel string `yaml:"embedding_model"`
+	} `yaml:"model"`
+	JWT struct {
+		SecretKey string `yaml:"secret_key"`
+	} `yaml:"jwt"`
+}
+
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"db_name"`
+}
+
+// Load 读取并解析 YAML 配置文件
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,40 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/config"
+	"errors"
+)
+
+// DAO 汇总服务依赖的各类存储
+type DAO struct {
+	HealthData     HealthDataStore
+	KnowledgeGraph KnowledgeGraph
+}
+
+// Open 根据配置创建健康数据存储和知识图谱后端
+func Open(ctx context.Context, cfg *config.Config) (*DAO, error) {
+	healthData, err := NewHealthDataStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := NewKnowledgeGraph(ctx, cfg)
+	if err != nil {
+		healthData.Close(ctx)
+		return nil, err
+	}
+
+	return &DAO{
+		HealthData:     healthData,
+		KnowledgeGraph: graph,
+	}, nil
+}
+
+// Close 关闭健康数据存储和知识图谱的连接
+func (d *DAO) Close(ctx context.Context) error {
+	return errors.Join(
+		d.HealthData.Close(ctx),
+		d.KnowledgeGraph.Close(ctx),
+	)
+}
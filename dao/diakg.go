@@ -0,0 +1,23 @@
+package dao
+
+import (
+	"diabetes-care-mcp-server/model"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ReadDiaKGDocument 读取并解析一个 DiaKG JSON 文件
+func ReadDiaKGDocument(path string) (*model.DiaKGDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", path, err)
+	}
+
+	var doc model.DiaKGDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error unmarshaling JSON: %v", err)
+	}
+
+	return &doc, nil
+}
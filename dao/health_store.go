@@ -0,0 +1,86 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/config"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"time"
+)
+
+const (
+	HealthDataBackendMySQL  = "mysql"
+	HealthDataBackendSQLite = "sqlite"
+	HealthDataBackendMemory = "memory"
+)
+
+// HealthDataStore 用户健康数据的存储接口，所有操作均以用户邮箱隔离
+type HealthDataStore interface {
+	// GetBloodGlucoseRecords 按测量时间倒序返回满足条件的血糖记录
+	GetBloodGlucoseRecords(ctx context.Context, email string, query RecordQuery) ([]model.BloodGlucoseRecord, error)
+	// GetHealthProfile 返回健康档案，不存在时返回 nil
+	GetHealthProfile(ctx context.Context, email string) (*model.HealthProfile, error)
+	// GetExerciseRecords 按开始时间倒序返回满足条件的运动记录
+	GetExerciseRecords(ctx context.Context, email string, query RecordQuery) ([]model.ExerciseRecord, error)
+
+	CreateBloodGlucoseRecord(ctx context.Context, email string, record *model.BloodGlucoseRecord) error
+	CreateExerciseRecord(ctx context.Context, email string, record *model.ExerciseRecord) error
+	// UpdateHealthProfile 更新档案中的指定字段（key 为列名），档案不存在时创建
+	UpdateHealthProfile(ctx context.Context, email string, updates map[string]any) error
+
+	// GetMedicationRegimens 按开始日期倒序返回全部用药方案
+	GetMedicationRegimens(ctx context.Context, email string) ([]model.MedicationRegimen, error)
+	CreateMedicationRegimen(ctx context.Context, email string, regimen *model.MedicationRegimen) error
+	// StopMedicationRegimen 设置方案的停药日期，方案不存在时返回 false
+	StopMedicationRegimen(ctx context.Context, email string, id uint, stopDate time.Time) (bool, error)
+	// GetMedicationDoses 按服药时间倒序返回满足条件的服药记录
+	GetMedicationDoses(ctx context.Context, email string, query RecordQuery) ([]model.MedicationDose, error)
+	CreateMedicationDose(ctx context.Context, email string, dose *model.MedicationDose) error
+
+	Close(ctx context.Context) error
+}
+
+// RecordQuery 血糖/运动记录的查询条件，零值字段不参与过滤
+type RecordQuery struct {
+	// Start 和 End 限定记录时间，区间为 [Start, End)
+	Start time.Time
+	End   time.Time
+	// DiningStatus 仅对血糖记录生效
+	DiningStatus string
+	// ExerciseType 仅对运动记录生效
+	ExerciseType string
+	// RegimenID 仅对服药记录生效
+	RegimenID uint
+	// After 为上一页最后一条记录的位置，返回严格位于其后的记录
+	After *Cursor
+	// Limit 为 0 时不限制条数
+	Limit int
+}
+
+// Cursor 记录在 (时间 DESC, ID DESC) 排序下的位置
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   uint      `json:"id"`
+}
+
+// Precedes 判断 c 在排序中是否位于 other 之前
+func (c Cursor) Precedes(other Cursor) bool {
+	if !c.Time.Equal(other.Time) {
+		return c.Time.After(other.Time)
+	}
+	return c.ID > other.ID
+}
+
+// NewHealthDataStore 根据配置创建健康数据存储
+func NewHealthDataStore(cfg *config.Config) (HealthDataStore, error) {
+	switch cfg.DB.HealthData {
+	case "", HealthDataBackendMySQL:
+		return NewMySQLHealthDataStore(cfg.DB.MySQL)
+	case HealthDataBackendSQLite:
+		return NewSQLiteHealthDataStore(cfg.DB.SQLite.Path)
+	case HealthDataBackendMemory:
+		return NewMemoryHealthDataStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown health data backend: %s", cfg.DB.HealthData)
+	}
+}
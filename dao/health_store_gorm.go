@@ -0,0 +1,332 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/config"
+	"diabetes-care-mcp-server/model"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	bloodGlucoseRecordTableName = "blood_glucose_record"
+	healthProfileTableName      = "health_profile"
+	exerciseRecordTableName     = "exercise_record"
+	medicationRegimenTableName  = "medication_regimen"
+	medicationDoseTableName     = "medication_dose"
+)
+
+// 带用户邮箱的表结构，用于写入和 SQLite 建表
+type bloodGlucoseRecordRow struct {
+	UserEmail string `gorm:"index"`
+	model.BloodGlucoseRecord
+}
+
+func (bloodGlucoseRecordRow) TableName() string { return bloodGlucoseRecordTableName }
+
+type healthProfileRow struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserEmail string `gorm:"uniqueIndex"`
+	model.HealthProfile
+}
+
+func (healthProfileRow) TableName() string { return healthProfileTableName }
+
+type exerciseRecordRow struct {
+	UserEmail string `gorm:"index"`
+	model.ExerciseRecord
+}
+
+func (exerciseRecordRow) TableName() string { return exerciseRecordTableName }
+
+type medicationRegimenRow struct {
+	UserEmail string `gorm:"index"`
+	model.MedicationRegimen
+}
+
+func (medicationRegimenRow) TableName() string { return medicationRegimenTableName }
+
+type medicationDoseRow struct {
+	UserEmail string `gorm:"index"`
+	model.MedicationDose
+}
+
+func (medicationDoseRow) TableName() string { return medicationDoseTableName }
+
+type gormHealthDataStore struct {
+	db *gorm.DB
+}
+
+// NewMySQLHealthDataStore 连接 MySQL，表结构由业务后端维护，本服务新增的表和列在连接后补齐（见 README）
+func NewMySQLHealthDataStore(dbConfig config.DBConfig) (HealthDataStore, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		dbConfig.Username,
+		dbConfig.Password,
+		dbConfig.Host,
+		dbConfig.Port,
+		dbConfig.DBName,
+	)
+
+	db, err := gorm.Open(mysql.Open(dsn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect database: %w", err)
+	}
+
+	if err := createMissingTables(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate mysql database: %w", err)
+	}
+	if err := addMissingColumns(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate mysql database: %w", err)
+	}
+
+	return &gormHealthDataStore{db: db}, nil
+}
+
+// 本服务新增、业务后端中不存在的表
+var addedTables = []any{
+	&medicationRegimenRow{},
+	&medicationDoseRow{},
+}
+
+// createMissingTables 创建本服务新增的表，已存在的表不做修改，可重复执行
+func createMissingTables(db *gorm.DB) error {
+	m := db.Migrator()
+	for _, table := range addedTables {
+		if m.HasTable(table) {
+			continue
+		}
+		if err := m.CreateTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 本服务在业务后端表结构之外新增的列
+var addedColumns = []struct {
+	table any
+	field string
+}{
+	{&healthProfileRow{}, "GlucoseUnit"},
+	{&bloodGlucoseRecordRow{}, "GlucoseUnit"},
+	{&exerciseRecordRow{}, "GlucoseUnit"},
+}
+
+// addMissingColumns 为已有的表补齐本服务新增的列，已存在的列不做修改，可重复执行
+func addMissingColumns(db *gorm.DB) error {
+	m := db.Migrator()
+	for _, c := range addedColumns {
+		if m.HasColumn(c.table, c.field) {
+			continue
+		}
+		if err := m.AddColumn(c.table, c.field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewSQLiteHealthDataStore 打开 SQLite 数据库并自动建表，path 为空时使用内存数据库
+func NewSQLiteHealthDataStore(path string) (HealthDataStore, error) {
+	if path == "" {
+		path = "file::memory:?cache=shared"
+	}
+
+	db, err := gorm.Open(sqlite.Open(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	if err := db.AutoMigrate(
+		&bloodGlucoseRecordRow{}, &healthProfileRow{}, &exerciseRecordRow{},
+		&medicationRegimenRow{}, &medicationDoseRow{},
+	); err != nil {
+		return nil, fmt.Errorf("failed to migrate sqlite database: %w", err)
+	}
+
+	return &gormHealthDataStore{db: db}, nil
+}
+
+func (s *gormHealthDataStore) Close(ctx context.Context) error {
+	db, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+func (s *gormHealthDataStore) GetBloodGlucoseRecords(ctx context.Context, email string, query RecordQuery) ([]model.BloodGlucoseRecord, error) {
+	db := s.db.WithContext(ctx).Table(bloodGlucoseRecordTableName).
+		Select("id, value, measured_at, dining_status, glucose_unit").
+		Where("user_email = ?", email)
+	if query.DiningStatus != "" {
+		db = db.Where("dining_status = ?", query.DiningStatus)
+	}
+
+	var records []model.BloodGlucoseRecord
+	err := applyRecordQuery(db, "measured_at", query).Find(&records).Error
+	return records, err
+}
+
+func (s *gormHealthDataStore) GetHealthProfile(ctx context.Context, email string) (*model.HealthProfile, error) {
+	var profile model.HealthProfile
+	err := s.db.WithContext(ctx).Table(healthProfileTableName).
+		Select("gender, age, height, weight, dietary_preference, smoking_status, activity_level, diabetes_type, diagnosis_year, therapy_mode, medication, allergies, complications, glucose_unit").
+		Where("user_email = ?", email).
+		First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (s *gormHealthDataStore) GetExerciseRecords(ctx context.Context, email string, query RecordQuery) ([]model.ExerciseRecord, error) {
+	db := s.db.WithContext(ctx).Table(exerciseRecordTableName).
+		Select("id, type, name, intensity, start_at, end_at, duration, pre_glucose, post_glucose, notes, glucose_unit").
+		Where("user_email = ?", email)
+	if query.ExerciseType != "" {
+		db = db.Where("type = ?", query.ExerciseType)
+	}
+
+	var records []model.ExerciseRecord
+	err := applyRecordQuery(db, "start_at", query).Find(&records).Error
+	return records, err
+}
+
+func (s *gormHealthDataStore) CreateBloodGlucoseRecord(ctx context.Context, email string, record *model.BloodGlucoseRecord) error {
+	row := bloodGlucoseRecordRow{
+		UserEmail:          email,
+		BloodGlucoseRecord: *record,
+	}
+	if err := s.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	record.ID = row.ID
+	return nil
+}
+
+func (s *gormHealthDataStore) CreateExerciseRecord(ctx context.Context, email string, record *model.ExerciseRecord) error {
+	row := exerciseRecordRow{
+		UserEmail:      email,
+		ExerciseRecord: *record,
+	}
+	if err := s.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	record.ID = row.ID
+	return nil
+}
+
+func (s *gormHealthDataStore) UpdateHealthProfile(ctx context.Context, email string, updates map[string]any) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Table(healthProfileTableName).Where("user_email = ?", email).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return tx.Table(healthProfileTableName).Where("user_email = ?", email).Updates(updates).Error
+		}
+
+		row := map[string]any{"user_email": email}
+		for k, v := range updates {
+			row[k] = v
+		}
+		return tx.Table(healthProfileTableName).Create(row).Error
+	})
+}
+
+func (s *gormHealthDataStore) GetMedicationRegimens(ctx context.Context, email string) ([]model.MedicationRegimen, error) {
+	var regimens []model.MedicationRegimen
+	err := s.db.WithContext(ctx).Table(medicationRegimenTableName).
+		Select("id, drug, dose, unit, frequency, route, start_date, stop_date, entity_id, notes").
+		Where("user_email = ?", email).
+		Order("start_date DESC").Order("id DESC").
+		Find(&regimens).Error
+	return regimens, err
+}
+
+func (s *gormHealthDataStore) CreateMedicationRegimen(ctx context.Context, email string, regimen *model.MedicationRegimen) error {
+	row := medicationRegimenRow{
+		UserEmail:         email,
+		MedicationRegimen: *regimen,
+	}
+	if err := s.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	regimen.ID = row.ID
+	return nil
+}
+
+// StopMedicationRegimen 先查询方案是否存在：停药日期不变时 MySQL 报告的影响行数为 0，不能据此判断
+func (s *gormHealthDataStore) StopMedicationRegimen(ctx context.Context, email string, id uint, stopDate time.Time) (bool, error) {
+	found := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Table(medicationRegimenTableName).Where("user_email = ? AND id = ?", email, id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+
+		found = true
+		return tx.Table(medicationRegimenTableName).
+			Where("user_email = ? AND id = ?", email, id).
+			Update("stop_date", stopDate).Error
+	})
+	return found, err
+}
+
+func (s *gormHealthDataStore) GetMedicationDoses(ctx context.Context, email string, query RecordQuery) ([]model.MedicationDose, error) {
+	db := s.db.WithContext(ctx).Table(medicationDoseTableName).
+		Select("id, regimen_id, status, taken_at, notes").
+		Where("user_email = ?", email)
+	if query.RegimenID != 0 {
+		db = db.Where("regimen_id = ?", query.RegimenID)
+	}
+
+	var doses []model.MedicationDose
+	err := applyRecordQuery(db, "taken_at", query).Find(&doses).Error
+	return doses, err
+}
+
+func (s *gormHealthDataStore) CreateMedicationDose(ctx context.Context, email string, dose *model.MedicationDose) error {
+	row := medicationDoseRow{
+		UserEmail:      email,
+		MedicationDose: *dose,
+	}
+	if err := s.db.WithContext(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	dose.ID = row.ID
+	return nil
+}
+
+// 按时间区间、游标和条数限制构建查询，结果按 (timeColumn DESC, id DESC) 排序
+func applyRecordQuery(db *gorm.DB, timeColumn string, query RecordQuery) *gorm.DB {
+	if !query.Start.IsZero() {
+		db = db.Where(timeColumn+" >= ?", query.Start)
+	}
+	if !query.End.IsZero() {
+		db = db.Where(timeColumn+" < ?", query.End)
+	}
+	if query.After != nil {
+		db = db.Where("("+timeColumn+" < ? OR ("+timeColumn+" = ? AND id < ?))",
+			query.After.Time, query.After.Time, query.After.ID)
+	}
+
+	db = db.Order(timeColumn + " DESC").Order("id DESC")
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	return db
+}
@@ -0,0 +1,213 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// memoryHealthDataStore 基于内存的健康数据存储，用于测试和演示
+type memoryHealthDataStore struct {
+	mu              sync.RWMutex
+	nextID          uint
+	glucoseRecords  map[string][]model.BloodGlucoseRecord
+	profiles        map[string]*model.HealthProfile
+	exerciseRecords map[string][]model.ExerciseRecord
+	regimens        map[string][]model.MedicationRegimen
+	doses           map[string][]model.MedicationDose
+}
+
+func NewMemoryHealthDataStore() HealthDataStore {
+	return &memoryHealthDataStore{
+		glucoseRecords:  make(map[string][]model.BloodGlucoseRecord),
+		profiles:        make(map[string]*model.HealthProfile),
+		exerciseRecords: make(map[string][]model.ExerciseRecord),
+		regimens:        make(map[string][]model.MedicationRegimen),
+		doses:           make(map[string][]model.MedicationDose),
+	}
+}
+
+func (s *memoryHealthDataStore) Close(ctx context.Context) error {
+	return nil
+}
+
+func (s *memoryHealthDataStore) GetBloodGlucoseRecords(ctx context.Context, email string, query RecordQuery) ([]model.BloodGlucoseRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filterRecords(s.glucoseRecords[email], query,
+		func(r model.BloodGlucoseRecord) Cursor { return Cursor{Time: r.MeasuredAt, ID: r.ID} },
+		func(r model.BloodGlucoseRecord) bool {
+			return query.DiningStatus == "" || r.DiningStatus == query.DiningStatus
+		},
+	), nil
+}
+
+func (s *memoryHealthDataStore) GetHealthProfile(ctx context.Context, email string) (*model.HealthProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, ok := s.profiles[email]
+	if !ok {
+		return nil, nil
+	}
+	p := *profile
+	return &p, nil
+}
+
+func (s *memoryHealthDataStore) GetExerciseRecords(ctx context.Context, email string, query RecordQuery) ([]model.ExerciseRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filterRecords(s.exerciseRecords[email], query,
+		func(r model.ExerciseRecord) Cursor { return Cursor{Time: r.StartAt, ID: r.ID} },
+		func(r model.ExerciseRecord) bool {
+			return query.ExerciseType == "" || r.Type == query.ExerciseType
+		},
+	), nil
+}
+
+func (s *memoryHealthDataStore) CreateBloodGlucoseRecord(ctx context.Context, email string, record *model.BloodGlucoseRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	record.ID = s.nextID
+	s.glucoseRecords[email] = append(s.glucoseRecords[email], *record)
+	return nil
+}
+
+func (s *memoryHealthDataStore) CreateExerciseRecord(ctx context.Context, email string, record *model.ExerciseRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	record.ID = s.nextID
+	s.exerciseRecords[email] = append(s.exerciseRecords[email], *record)
+	return nil
+}
+
+func (s *memoryHealthDataStore) UpdateHealthProfile(ctx context.Context, email string, updates map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profile := model.HealthProfile{}
+	if p, ok := s.profiles[email]; ok {
+		profile = *p
+	}
+
+	// 档案的 json tag 与列名一致，按 tag 将更新字段写入结构体
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:          "json",
+		WeaklyTypedInput: true,
+		Result:           &profile,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(updates); err != nil {
+		return fmt.Errorf("failed to apply profile updates: %w", err)
+	}
+
+	s.profiles[email] = &profile
+	return nil
+}
+
+func (s *memoryHealthDataStore) GetMedicationRegimens(ctx context.Context, email string) ([]model.MedicationRegimen, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	regimens := slices.Clone(s.regimens[email])
+	sort.SliceStable(regimens, func(i, j int) bool {
+		a, b := regimens[i], regimens[j]
+		if !a.StartDate.Equal(b.StartDate) {
+			return a.StartDate.After(b.StartDate)
+		}
+		return a.ID > b.ID
+	})
+	return regimens, nil
+}
+
+func (s *memoryHealthDataStore) CreateMedicationRegimen(ctx context.Context, email string, regimen *model.MedicationRegimen) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	regimen.ID = s.nextID
+	s.regimens[email] = append(s.regimens[email], *regimen)
+	return nil
+}
+
+func (s *memoryHealthDataStore) StopMedicationRegimen(ctx context.Context, email string, id uint, stopDate time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.regimens[email] {
+		if r := &s.regimens[email][i]; r.ID == id {
+			r.StopDate = &stopDate
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryHealthDataStore) GetMedicationDoses(ctx context.Context, email string, query RecordQuery) ([]model.MedicationDose, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return filterRecords(s.doses[email], query,
+		func(d model.MedicationDose) Cursor { return Cursor{Time: d.TakenAt, ID: d.ID} },
+		func(d model.MedicationDose) bool {
+			return query.RegimenID == 0 || d.RegimenID == query.RegimenID
+		},
+	), nil
+}
+
+func (s *memoryHealthDataStore) CreateMedicationDose(ctx context.Context, email string, dose *model.MedicationDose) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	dose.ID = s.nextID
+	s.doses[email] = append(s.doses[email], *dose)
+	return nil
+}
+
+// 按查询条件过滤记录，排序与游标语义与 GORM 实现一致
+func filterRecords[T any](records []T, query RecordQuery, position func(T) Cursor, match func(T) bool) []T {
+	var result []T
+	for _, r := range records {
+		pos := position(r)
+		if !query.Start.IsZero() && pos.Time.Before(query.Start) {
+			continue
+		}
+		if !query.End.IsZero() && !pos.Time.Before(query.End) {
+			continue
+		}
+		if query.After != nil && !query.After.Precedes(pos) {
+			continue
+		}
+		if match(r) {
+			result = append(result, r)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return position(result[i]).Precedes(position(result[j]))
+	})
+
+	return truncate(result, query.Limit)
+}
+
+func truncate[T any](records []T, limit int) []T {
+	if limit > 0 && len(records) > limit {
+		return records[:limit]
+	}
+	return records
+}
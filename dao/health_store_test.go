@@ -0,0 +1,326 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/model"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var baseTime = time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+func at(hours int) time.Time {
+	return baseTime.Add(time.Duration(hours) * time.Hour)
+}
+
+func TestCursorPrecedes(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     Cursor
+		other Cursor
+		want  bool
+	}{
+		{"later time first", Cursor{Time: at(2), ID: 1}, Cursor{Time: at(1), ID: 9}, true},
+		{"earlier time after", Cursor{Time: at(1), ID: 9}, Cursor{Time: at(2), ID: 1}, false},
+		{"same time larger id first", Cursor{Time: at(1), ID: 5}, Cursor{Time: at(1), ID: 3}, true},
+		{"same time smaller id after", Cursor{Time: at(1), ID: 3}, Cursor{Time: at(1), ID: 5}, false},
+		{"identical", Cursor{Time: at(1), ID: 3}, Cursor{Time: at(1), ID: 3}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.Precedes(tt.other); got != tt.want {
+				t.Errorf("Precedes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRecords(t *testing.T) {
+	type record struct {
+		id   uint
+		time time.Time
+		kind string
+	}
+	records := []record{
+		{1, at(0), "a"},
+		{2, at(1), "b"},
+		{3, at(1), "a"},
+		{4, at(3), "a"},
+		{5, at(2), "b"},
+	}
+	position := func(r record) Cursor { return Cursor{Time: r.time, ID: r.id} }
+
+	tests := []struct {
+		name  string
+		query RecordQuery
+		kind  string
+		want  []uint
+	}{
+		{"all newest first, ties by id", RecordQuery{}, "", []uint{4, 5, 3, 2, 1}},
+		{"start inclusive", RecordQuery{Start: at(1)}, "", []uint{4, 5, 3, 2}},
+		{"end exclusive", RecordQuery{End: at(2)}, "", []uint{3, 2, 1}},
+		{"limit", RecordQuery{Limit: 2}, "", []uint{4, 5}},
+		{"after cursor within tie", RecordQuery{After: &Cursor{Time: at(1), ID: 3}}, "", []uint{2, 1}},
+		{"after cursor and limit", RecordQuery{After: &Cursor{Time: at(2), ID: 5}, Limit: 2}, "", []uint{3, 2}},
+		{"match filter", RecordQuery{}, "a", []uint{4, 3, 1}},
+		{"empty window", RecordQuery{Start: at(5)}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterRecords(records, tt.query, position, func(r record) bool {
+				return tt.kind == "" || r.kind == tt.kind
+			})
+			var ids []uint
+			for _, r := range got {
+				ids = append(ids, r.id)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("ids = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+// 各后端需要满足相同的排序、分页和档案更新语义
+func healthDataStores(t *testing.T) map[string]HealthDataStore {
+	t.Helper()
+	sqliteStore, err := NewSQLiteHealthDataStore(filepath.Join(t.TempDir(), "health.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteHealthDataStore: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := sqliteStore.Close(context.Background()); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	return map[string]HealthDataStore{
+		HealthDataBackendMemory: NewMemoryHealthDataStore(),
+		HealthDataBackendSQLite: sqliteStore,
+	}
+}
+
+func TestHealthDataStoreGlucoseQuery(t *testing.T) {
+	ctx := context.Background()
+	const email = "user@example.com"
+
+	for backend, store := range healthDataStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			// 写入顺序与时间顺序不同，且包含同一时刻的多条记录
+			inputs := []struct {
+				hour   int
+				status string
+			}{
+				{0, "fasting"},
+				{2, "after_meal"},
+				{1, "fasting"},
+				{2, "fasting"},
+				{3, "bedtime"},
+			}
+			ids := make([]uint, len(inputs))
+			for i, in := range inputs {
+				r := model.BloodGlucoseRecord{Value: 6, MeasuredAt: at(in.hour), DiningStatus: in.status}
+				if err := store.CreateBloodGlucoseRecord(ctx, email, &r); err != nil {
+					t.Fatalf("CreateBloodGlucoseRecord: %v", err)
+				}
+				ids[i] = r.ID
+			}
+			// 其他用户的数据不可见
+			other := model.BloodGlucoseRecord{Value: 7, MeasuredAt: at(1), DiningStatus: "fasting"}
+			if err := store.CreateBloodGlucoseRecord(ctx, "other@example.com", &other); err != nil {
+				t.Fatalf("CreateBloodGlucoseRecord: %v", err)
+			}
+
+			tests := []struct {
+				name  string
+				query RecordQuery
+				want  []uint
+			}{
+				{"time desc then id desc", RecordQuery{}, []uint{ids[4], ids[3], ids[1], ids[2], ids[0]}},
+				{"window", RecordQuery{Start: at(1), End: at(3)}, []uint{ids[3], ids[1], ids[2]}},
+				{"dining status", RecordQuery{DiningStatus: "fasting"}, []uint{ids[3], ids[2], ids[0]}},
+				{"first page", RecordQuery{Limit: 2}, []uint{ids[4], ids[3]}},
+				{"next page", RecordQuery{After: &Cursor{Time: at(2), ID: ids[3]}, Limit: 2}, []uint{ids[1], ids[2]}},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					records, err := store.GetBloodGlucoseRecords(ctx, email, tt.query)
+					if err != nil {
+						t.Fatalf("GetBloodGlucoseRecords: %v", err)
+					}
+					var got []uint
+					for _, r := range records {
+						got = append(got, r.ID)
+					}
+					if !slices.Equal(got, tt.want) {
+						t.Errorf("ids = %v, want %v", got, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestHealthDataStoreProfileUpdate(t *testing.T) {
+	ctx := context.Background()
+	const email = "user@example.com"
+
+	for backend, store := range healthDataStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			profile, err := store.GetHealthProfile(ctx, email)
+			if err != nil || profile != nil {
+				t.Fatalf("GetHealthProfile before create = %v, %v; want nil, nil", profile, err)
+			}
+
+			steps := []struct {
+				name    string
+				updates map[string]any
+				want    model.HealthProfile
+			}{
+				{
+					"create",
+					map[string]any{"gender": "female", "age": 52, "smoking_status": true},
+					model.HealthProfile{Gender: "female", Age: 52, SmokingStatus: true},
+				},
+				{
+					"partial update keeps other fields",
+					map[string]any{"weight": 63.5, "medication": "二甲双胍"},
+					model.HealthProfile{Gender: "female", Age: 52, SmokingStatus: true, Weight: 63.5, Medication: "二甲双胍"},
+				},
+				{
+					"zero values are written",
+					map[string]any{"smoking_status": false, "medication": ""},
+					model.HealthProfile{Gender: "female", Age: 52, Weight: 63.5},
+				},
+			}
+			for _, step := range steps {
+				if err := store.UpdateHealthProfile(ctx, email, step.updates); err != nil {
+					t.Fatalf("%s: UpdateHealthProfile: %v", step.name, err)
+				}
+				profile, err := store.GetHealthProfile(ctx, email)
+				if err != nil || profile == nil {
+					t.Fatalf("%s: GetHealthProfile = %v, %v", step.name, profile, err)
+				}
+				if *profile != step.want {
+					t.Errorf("%s: profile = %+v, want %+v", step.name, *profile, step.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthDataStoreStopMedicationRegimen(t *testing.T) {
+	ctx := context.Background()
+	const email = "user@example.com"
+
+	for backend, store := range healthDataStores(t) {
+		t.Run(backend, func(t *testing.T) {
+			regimen := model.MedicationRegimen{Drug: "二甲双胍", Frequency: "twice_daily", StartDate: at(0)}
+			if err := store.CreateMedicationRegimen(ctx, email, &regimen); err != nil {
+				t.Fatalf("CreateMedicationRegimen: %v", err)
+			}
+
+			steps := []struct {
+				name  string
+				email string
+				id    uint
+				want  bool
+			}{
+				{"existing regimen", email, regimen.ID, true},
+				// 停药日期不变时影响行数可能为 0，仍应报告方案存在
+				{"unchanged stop date", email, regimen.ID, true},
+				{"other user's regimen", "other@example.com", regimen.ID, false},
+				{"unknown regimen", email, regimen.ID + 1, false},
+			}
+			for _, step := range steps {
+				found, err := store.StopMedicationRegimen(ctx, step.email, step.id, at(24))
+				if err != nil {
+					t.Fatalf("%s: StopMedicationRegimen: %v", step.name, err)
+				}
+				if found != step.want {
+					t.Errorf("%s: found = %v, want %v", step.name, found, step.want)
+				}
+			}
+
+			regimens, err := store.GetMedicationRegimens(ctx, email)
+			if err != nil || len(regimens) != 1 {
+				t.Fatalf("GetMedicationRegimens = %v, %v; want one regimen", regimens, err)
+			}
+			if stop := regimens[0].StopDate; stop == nil || !stop.Equal(at(24)) {
+				t.Errorf("StopDate = %v, want %v", stop, at(24))
+			}
+		})
+	}
+}
+
+func TestAddMissingColumns(t *testing.T) {
+	store, err := NewSQLiteHealthDataStore(filepath.Join(t.TempDir(), "health.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteHealthDataStore: %v", err)
+	}
+	defer store.Close(context.Background())
+	db := store.(*gormHealthDataStore).db
+
+	// 模拟业务后端维护的旧表结构
+	for _, c := range addedColumns {
+		if err := db.Migrator().DropColumn(c.table, c.field); err != nil {
+			t.Fatalf("DropColumn: %v", err)
+		}
+		if db.Migrator().HasColumn(c.table, c.field) {
+			t.Fatalf("column %s not dropped on %T", c.field, c.table)
+		}
+	}
+
+	for i := range 2 {
+		if err := addMissingColumns(db); err != nil {
+			t.Fatalf("addMissingColumns run %d: %v", i+1, err)
+		}
+		for _, c := range addedColumns {
+			if !db.Migrator().HasColumn(c.table, c.field) {
+				t.Errorf("run %d: column %s missing on %T", i+1, c.field, c.table)
+			}
+		}
+	}
+}
+
+func TestCreateMissingTables(t *testing.T) {
+	store, err := NewSQLiteHealthDataStore(filepath.Join(t.TempDir(), "health.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteHealthDataStore: %v", err)
+	}
+	defer store.Close(context.Background())
+	db := store.(*gormHealthDataStore).db
+
+	// 模拟没有用药表的业务后端数据库
+	for _, table := range addedTables {
+		if err := db.Migrator().DropTable(table); err != nil {
+			t.Fatalf("DropTable: %v", err)
+		}
+	}
+
+	for i := range 2 {
+		if err := createMissingTables(db); err != nil {
+			t.Fatalf("createMissingTables run %d: %v", i+1, err)
+		}
+		for _, table := range addedTables {
+			if !db.Migrator().HasTable(table) {
+				t.Errorf("run %d: table %T missing", i+1, table)
+			}
+		}
+	}
+
+	// 已有数据的表不被重建
+	ctx := context.Background()
+	regimen := model.MedicationRegimen{Drug: "二甲双胍", StartDate: baseTime}
+	if err := store.CreateMedicationRegimen(ctx, "user@example.com", &regimen); err != nil {
+		t.Fatalf("CreateMedicationRegimen: %v", err)
+	}
+	if err := createMissingTables(db); err != nil {
+		t.Fatalf("createMissingTables: %v", err)
+	}
+	regimens, err := store.GetMedicationRegimens(ctx, "user@example.com")
+	if err != nil || len(regimens) != 1 {
+		t.Errorf("GetMedicationRegimens = %v, %v; want one regimen", regimens, err)
+	}
+}
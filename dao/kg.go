@@ -0,0 +1,90 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/config"
+	"diabetes-care-mcp-server/embedding"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+)
+
+const (
+	KnowledgeGraphBackendNeo4j  = "neo4j"
+	KnowledgeGraphBackendMemory = "memory"
+
+	defaultKnowledgeGraphData = "resource/diakg/*.json"
+)
+
+// KnowledgeGraph 糖尿病知识图谱的查询接口
+type KnowledgeGraph interface {
+	// SearchEntities 根据关键词模糊匹配实体名称和别名，并按向量相似度召回语义相近的实体，
+	// 返回至少存在一个符合条件关系的实体，按融合得分降序
+	SearchEntities(ctx context.Context, keywords []string, opts SearchOptions) ([]model.KnowlegeGraphSearchResult, error)
+	// GetEntity 按归一化后的名称或别名精确查找实体，名称匹配优先，不存在时返回 nil
+	GetEntity(ctx context.Context, name string) (*model.EntityNode, error)
+	// ListEntities 返回图谱中的全部实体，用于构建实体词典
+	ListEntities(ctx context.Context) ([]model.EntityNode, error)
+	// GetNeighbours 返回与指定名称实体直接相连的关系，按权重降序
+	GetNeighbours(ctx context.Context, name string, limit int) ([]model.Relation, error)
+	// GetEntityDetails 按实体 ID 或名称返回 hops 跳以内的关系和抽取该实体的原文，实体不存在时返回 nil
+	GetEntityDetails(ctx context.Context, key string, opts EntityDetailsOptions) (*model.EntityDetails, error)
+	// FindPaths 返回 ID 为 from 与 to 的实体之间最短的至多 K 条简单路径，按长度升序；Neo4j 后端只返回长度最短的路径
+	FindPaths(ctx context.Context, from, to string, opts PathOptions) ([]model.Path, error)
+	// SearchPassages 根据关键词全文检索指南原文的句子和段落，并按向量相似度召回语义相近的句子，按融合得分降序
+	SearchPassages(ctx context.Context, keywords []string, opts PassageOptions) ([]model.Passage, error)
+	Close(ctx context.Context) error
+}
+
+type SearchOptions struct {
+	Limit int
+	// Query 原始查询文本，用于计算查询向量，为空时使用关键词
+	Query string
+	// EntityTypes 非空时只返回这些类型的实体
+	EntityTypes []string
+	// RelationTypes 非空时只返回这些类型的关系，没有符合条件关系的实体不会返回
+	RelationTypes []string
+}
+
+type PassageOptions struct {
+	Limit int
+	// Query 原始查询文本，用于计算查询向量，为空时使用关键词
+	Query string
+	// Level 为 sentence 或 paragraph 时只返回该粒度的原文，为空时两者都返回
+	Level string
+}
+
+type EntityDetailsOptions struct {
+	Hops          int
+	RelationLimit int
+	EvidenceLimit int
+}
+
+type PathOptions struct {
+	// MaxLength 路径包含的最大关系数
+	MaxLength int
+	// K 返回的路径条数
+	K int
+	// RelationTypes 非空时路径只能经过这些类型的关系
+	RelationTypes []string
+}
+
+// NewKnowledgeGraph 根据配置创建知识图谱后端
+func NewKnowledgeGraph(ctx context.Context, cfg *config.Config) (KnowledgeGraph, error) {
+	embedder, err := embedding.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.DB.KnowledgeGraph {
+	case "", KnowledgeGraphBackendNeo4j:
+		return NewNeo4jKnowledgeGraph(ctx, cfg.DB.Neo4j, embedder)
+	case KnowledgeGraphBackendMemory:
+		pattern := cfg.DB.KnowledgeGraphData
+		if pattern == "" {
+			pattern = defaultKnowledgeGraphData
+		}
+		return LoadMemoryKnowledgeGraph(ctx, pattern, cfg.DB.KnowledgeGraphAliases, embedder)
+	default:
+		return nil, fmt.Errorf("unknown knowledge graph backend: %s", cfg.DB.KnowledgeGraph)
+	}
+}
@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"bytes"
+	"diabetes-care-mcp-server/model"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadAliases 读取别名词典 CSV：表头包含 name、alias 列，type 列可省略，
+// 每行为规范实体名称的一个别名（商品名/通用名、缩写、中英文名称等）
+func ReadAliases(path string) ([]model.EntityAlias, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", path, err)
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %v", err)
+	}
+	columns := csvColumns(header)
+	for _, name := range []string{"name", "alias"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("alias CSV header must contain columns name, alias and optionally type")
+		}
+	}
+
+	var aliases []model.EntityAlias
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %v", err)
+		}
+
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		alias := model.EntityAlias{Name: get("name"), Type: get("type"), Alias: get("alias")}
+		if alias.Name == "" || alias.Alias == "" {
+			return nil, fmt.Errorf("line %d: name and alias are required", line)
+		}
+		if alias.Type != "" && !model.IsDiaKGEntityType(alias.Type) {
+			return nil, fmt.Errorf("line %d: unknown entity type %q", line, alias.Type)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
+
+// matchedAlias 实体名称不匹配任何关键词时，返回匹配关键词的第一个别名
+func matchedAlias(node model.EntityNode, terms []string) string {
+	if matchScore(node.Name, terms) > 0 {
+		return ""
+	}
+	for _, alias := range node.Aliases {
+		if matchScore(alias, terms) > 0 {
+			return alias
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"diabetes-care-mcp-server/model"
+	"sort"
+	"strings"
+)
+
+// 混合检索：全文得分按候选集中的最高分归一化到 [0, 1] 后，与查询和实体名称的向量余弦相似度加权求和
+const (
+	// vectorWeight 融合得分中向量相似度的权重
+	vectorWeight = 0.5
+	// minVectorSimilarity 仅由向量检索召回的实体需达到的最低相似度，避免返回不相关的实体
+	minVectorSimilarity = 0.3
+	// vectorCandidateFactor 向量检索召回的候选数为结果数的倍数
+	vectorCandidateFactor = 3
+)
+
+// searchCandidate 混合检索的候选实体，textScore 为全文得分，vectorScore 为余弦相似度
+type searchCandidate struct {
+	result      model.KnowlegeGraphSearchResult
+	textScore   float32
+	vectorScore float32
+}
+
+// passageCandidate 混合检索的候选原文
+type passageCandidate struct {
+	passage     model.Passage
+	textScore   float32
+	vectorScore float32
+}
+
+// fuseScores 计算融合得分写入 Score，按得分降序返回前 limit 个结果
+func fuseScores(candidates []searchCandidate, limit int) []model.KnowlegeGraphSearchResult {
+	var maxText float32
+	for _, c := range candidates {
+		maxText = max(maxText, c.textScore)
+	}
+
+	results := make([]model.KnowlegeGraphSearchResult, 0, len(candidates))
+	for _, c := range candidates {
+		r := c.result
+		r.Score = fusedScore(c.textScore, maxText, c.vectorScore)
+		results = append(results, r)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return truncate(results, limit)
+}
+
+// fusePassages 与 fuseScores 相同，用于原文检索
+func fusePassages(candidates []passageCandidate, limit int) []model.Passage {
+	var maxText float32
+	for _, c := range candidates {
+		maxText = max(maxText, c.textScore)
+	}
+
+	passages := make([]model.Passage, 0, len(candidates))
+	for _, c := range candidates {
+		p := c.passage
+		p.Score = fusedScore(c.textScore, maxText, c.vectorScore)
+		passages = append(passages, p)
+	}
+
+	sort.SliceStable(passages, func(i, j int) bool {
+		return passages[i].Score > passages[j].Score
+	})
+	return truncate(passages, limit)
+}
+
+func fusedScore(textScore, maxText, vectorScore float32) float32 {
+	var text float32
+	if maxText > 0 {
+		text = textScore / maxText
+	}
+	return (1-vectorWeight)*text + vectorWeight*max(vectorScore, 0)
+}
+
+// queryText 用于计算查询向量的文本，未提供原始查询时使用关键词
+func queryText(keywords []string, query string) string {
+	if query != "" {
+		return query
+	}
+	return strings.Join(keywords, " ")
+}
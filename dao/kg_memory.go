@@ -0,0 +1,744 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/embedding"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// memoryEntity 规范实体，同类型且归一化名称相同的实体标注合并为一个节点
+type memoryEntity struct {
+	key      string
+	node     model.EntityNode
+	edges    []memoryEdge
+	mentions []*memorySentence
+	// vector 实体名称的向量
+	vector []float32
+}
+
+// memoryEdge 从所属实体出发的一条关系，outgoing 表示所属实体为关系的头实体
+type memoryEdge struct {
+	relType  string
+	target   *memoryEntity
+	outgoing bool
+	rel      *memoryRelation
+}
+
+// memoryRelation 两个实体间同类型关系的标注记录，由两端实体的 memoryEdge 共享
+type memoryRelation struct {
+	ids     map[string]bool
+	sources []string
+}
+
+// weight 关系被标注的次数
+func (r *memoryRelation) weight() int {
+	return len(r.ids)
+}
+
+type memorySentence struct {
+	docID     string
+	paragraph *memoryParagraph
+	id        string
+	text      string
+	vector    []float32
+	// entities 句中标注的规范实体，按首次出现的顺序
+	entities []*memoryEntity
+}
+
+type memoryParagraph struct {
+	docID     string
+	id        string
+	text      string
+	sentences []*memorySentence
+}
+
+// memoryKnowledgeGraph 纯内存的知识图谱实现，直接从数据文件构建，无需 Neo4j
+type memoryKnowledgeGraph struct {
+	entities   []*memoryEntity
+	paragraphs []*memoryParagraph
+	sentences  []*memorySentence
+	byKey      map[string]*memoryEntity
+	// byName 以归一化名称索引，同名不同类型的实体对应多个节点
+	byName map[string][]*memoryEntity
+	// embedder 为空时只按名称匹配
+	embedder embedding.Embedder
+}
+
+func newMemoryKnowledgeGraph() *memoryKnowledgeGraph {
+	return &memoryKnowledgeGraph{
+		byKey:  make(map[string]*memoryEntity),
+		byName: make(map[string][]*memoryEntity),
+	}
+}
+
+// LoadMemoryKnowledgeGraph 读取匹配 pattern 的数据文件构建内存图谱，格式按扩展名识别；
+// aliasesPath 不为空时加载别名词典，embedder 不为空时计算实体和句子的向量
+func LoadMemoryKnowledgeGraph(ctx context.Context, pattern, aliasesPath string, embedder embedding.Embedder) (KnowledgeGraph, error) {
+	files, err := GlobKGFiles(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid knowledge graph data pattern: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no knowledge graph data found: %s", pattern)
+	}
+
+	g := newMemoryKnowledgeGraph()
+	for _, file := range files {
+		switch format := DetectKGFormat(file); format {
+		case KGFormatDiaKG:
+			doc, err := ReadDiaKGDocument(file)
+			if err != nil {
+				return nil, err
+			}
+			g.AddDocument(doc)
+		case "":
+			return nil, fmt.Errorf("unknown knowledge graph data format: %s", file)
+		default:
+			triples, err := ReadTriples(file, format)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s: %w", file, err)
+			}
+			g.AddTriples(triples, TripleSource(file))
+		}
+	}
+
+	if aliasesPath != "" {
+		aliases, err := ReadAliases(aliasesPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading aliases %s: %w", aliasesPath, err)
+		}
+		g.AddAliases(aliases)
+	}
+
+	if embedder != nil {
+		if err := g.embed(ctx, embedder); err != nil {
+			return nil, fmt.Errorf("error computing embeddings: %w", err)
+		}
+	}
+
+	return g, nil
+}
+
+// embed 计算实体名称和句子的向量，之后的检索按向量相似度召回语义相近的实体
+func (g *memoryKnowledgeGraph) embed(ctx context.Context, embedder embedding.Embedder) error {
+	names := make([]string, len(g.entities))
+	for i, entity := range g.entities {
+		names[i] = entity.node.Name
+	}
+	vectors, err := embedder.Embed(ctx, names)
+	if err != nil {
+		return err
+	}
+	for i, entity := range g.entities {
+		entity.vector = vectors[i]
+	}
+
+	texts := make([]string, len(g.sentences))
+	for i, sentence := range g.sentences {
+		texts[i] = sentence.text
+	}
+	if vectors, err = embedder.Embed(ctx, texts); err != nil {
+		return err
+	}
+	for i, sentence := range g.sentences {
+		sentence.vector = vectors[i]
+	}
+
+	g.embedder = embedder
+	return nil
+}
+
+// AddAliases 将别名附加到同名（及同类型）的规范实体上，并可按别名查找实体；没有对应实体的别名被忽略
+func (g *memoryKnowledgeGraph) AddAliases(aliases []model.EntityAlias) {
+	for _, a := range aliases {
+		name := model.NormalizeEntityName(a.Alias)
+		for _, entity := range g.lookup(a.Name) {
+			if a.Type != "" && entity.node.Type != a.Type {
+				continue
+			}
+			if name == model.NormalizeEntityName(entity.node.Name) || slices.Contains(g.byName[name], entity) {
+				continue
+			}
+			entity.node.Aliases = append(entity.node.Aliases, strings.TrimSpace(a.Alias))
+			g.byName[name] = append(g.byName[name], entity)
+		}
+	}
+}
+
+// AddDocument 将文档中的实体、关系及其出处加入图谱，类型不在 DiaKG 标注体系中或端点缺失的实体和关系被忽略
+func (g *memoryKnowledgeGraph) AddDocument(doc *model.DiaKGDocument) {
+	// 标注中的实体 ID 只在文档内唯一
+	mentions := make(map[string]*memoryEntity)
+	for _, para := range doc.Paragraphs {
+		paragraph := &memoryParagraph{docID: doc.DocID, id: para.ParagraphID, text: para.Paragraph}
+		g.paragraphs = append(g.paragraphs, paragraph)
+		for _, sentence := range para.Sentences {
+			ms := &memorySentence{
+				docID:     doc.DocID,
+				paragraph: paragraph,
+				id:        sentence.SentenceID,
+				text:      sentence.Sentence,
+			}
+			g.sentences = append(g.sentences, ms)
+			paragraph.sentences = append(paragraph.sentences, ms)
+			for _, e := range sentence.Entities {
+				if model.IsDiaKGEntityType(e.EntityType) {
+					mentions[e.EntityID] = g.addMention(e, ms)
+				}
+			}
+			for _, r := range sentence.Relations {
+				if !model.IsDiaKGRelationType(r.RelationType) {
+					continue
+				}
+				head, ok1 := mentions[r.HeadEntityID]
+				tail, ok2 := mentions[r.TailEntityID]
+				if !ok1 || !ok2 {
+					continue
+				}
+				id := model.DiaKGRelationID(doc.DocID, r.RelationID)
+				addRelation(head, tail, r.RelationType, id, model.DiaKGSource)
+			}
+		}
+	}
+}
+
+// AddTriples 加入外部数据集的三元组，Source 为空的三元组使用 defaultSource，不合法的三元组被忽略
+func (g *memoryKnowledgeGraph) AddTriples(triples []model.KGTriple, defaultSource string) {
+	for _, t := range triples {
+		if t.Validate() != nil {
+			continue
+		}
+		source := t.Source
+		if source == "" {
+			source = defaultSource
+		}
+		head := g.addEntity(t.Head, t.HeadType, source)
+		tail := g.addEntity(t.Tail, t.TailType, source)
+		id := model.TripleRelationID(source, head.key, t.Relation, tail.key)
+		addRelation(head, tail, t.Relation, id, source)
+	}
+}
+
+// 返回名称和类型对应的规范实体，不存在时以该名称创建
+func (g *memoryKnowledgeGraph) addEntity(name, entityType, source string) *memoryEntity {
+	key := model.CanonicalEntityKey(name, entityType)
+
+	entity, ok := g.byKey[key]
+	if !ok {
+		entity = &memoryEntity{
+			key:  key,
+			node: model.EntityNode{ID: key, Name: strings.TrimSpace(name), Type: entityType},
+		}
+		g.entities = append(g.entities, entity)
+		g.byKey[key] = entity
+		normalized := model.NormalizeEntityName(name)
+		g.byName[normalized] = append(g.byName[normalized], entity)
+	}
+	entity.node.Sources = addSource(entity.node.Sources, source)
+	return entity
+}
+
+// 将实体标注关联到规范实体，同一句中多次出现只记录一次出处
+func (g *memoryKnowledgeGraph) addMention(e model.DiaKGEntity, sentence *memorySentence) *memoryEntity {
+	entity := g.addEntity(e.Entity, e.EntityType, model.DiaKGSource)
+	if n := len(entity.mentions); n == 0 || entity.mentions[n-1] != sentence {
+		entity.mentions = append(entity.mentions, sentence)
+	}
+	if !slices.Contains(sentence.entities, entity) {
+		sentence.entities = append(sentence.entities, entity)
+	}
+	return entity
+}
+
+// 记录一次关系标注，同一对实体间同类型的关系合并，id 相同的标注只计一次
+func addRelation(head, tail *memoryEntity, relType, id, source string) {
+	var rel *memoryRelation
+	for _, edge := range head.edges {
+		if edge.outgoing && edge.relType == relType && edge.target == tail {
+			rel = edge.rel
+			break
+		}
+	}
+	if rel == nil {
+		rel = &memoryRelation{ids: make(map[string]bool)}
+		head.edges = append(head.edges, memoryEdge{relType: relType, target: tail, outgoing: true, rel: rel})
+		tail.edges = append(tail.edges, memoryEdge{relType: relType, target: head, rel: rel})
+	}
+	rel.ids[id] = true
+	rel.sources = addSource(rel.sources, source)
+}
+
+func addSource(sources []string, source string) []string {
+	if slices.Contains(sources, source) {
+		return sources
+	}
+	return append(sources, source)
+}
+
+// 按名称查找实体，名称先归一化
+func (g *memoryKnowledgeGraph) lookup(name string) []*memoryEntity {
+	return g.byName[model.NormalizeEntityName(name)]
+}
+
+func (g *memoryKnowledgeGraph) Close(ctx context.Context) error {
+	return nil
+}
+
+func (g *memoryKnowledgeGraph) SearchEntities(ctx context.Context, keywords []string, opts SearchOptions) ([]model.KnowlegeGraphSearchResult, error) {
+	terms := normalizeTerms(keywords)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("valid keywords not found")
+	}
+
+	var queryVector []float32
+	if g.embedder != nil {
+		vectors, err := g.embedder.Embed(ctx, []string{queryText(keywords, opts.Query)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+		queryVector = vectors[0]
+	}
+
+	var candidates []searchCandidate
+	for _, entity := range g.entities {
+		if len(opts.EntityTypes) > 0 && !slices.Contains(opts.EntityTypes, entity.node.Type) {
+			continue
+		}
+		score := matchScore(entity.node.Name, terms)
+		for _, alias := range entity.node.Aliases {
+			score = max(score, matchScore(alias, terms))
+		}
+		var similarity float32
+		if queryVector != nil {
+			similarity = embedding.Cosine(queryVector, entity.vector)
+		}
+		if score == 0 && similarity < minVectorSimilarity {
+			continue
+		}
+		relations := entity.relations(opts.RelationTypes)
+		if len(relations) == 0 {
+			continue
+		}
+		candidates = append(candidates, searchCandidate{
+			result: model.KnowlegeGraphSearchResult{
+				Node:          entity.node,
+				Relationships: relations,
+				Score:         score,
+				MatchedAlias:  matchedAlias(entity.node, terms),
+			},
+			textScore:   score,
+			vectorScore: similarity,
+		})
+	}
+
+	if queryVector != nil {
+		return fuseScores(candidates, opts.Limit), nil
+	}
+
+	results := make([]model.KnowlegeGraphSearchResult, len(candidates))
+	for i, c := range candidates {
+		results[i] = c.result
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return truncate(results, opts.Limit), nil
+}
+
+func (g *memoryKnowledgeGraph) GetEntity(ctx context.Context, name string) (*model.EntityNode, error) {
+	entities := g.lookup(name)
+	if len(entities) == 0 {
+		return nil, nil
+	}
+	node := entities[0].node
+	return &node, nil
+}
+
+func (g *memoryKnowledgeGraph) ListEntities(ctx context.Context) ([]model.EntityNode, error) {
+	entities := make([]model.EntityNode, len(g.entities))
+	for i, entity := range g.entities {
+		entities[i] = entity.node
+	}
+	return entities, nil
+}
+
+func (g *memoryKnowledgeGraph) GetNeighbours(ctx context.Context, name string, limit int) ([]model.Relation, error) {
+	var relations []model.Relation
+	for _, entity := range g.lookup(name) {
+		relations = append(relations, entity.relations(nil)...)
+	}
+	return truncate(relations, limit), nil
+}
+
+func (g *memoryKnowledgeGraph) GetEntityDetails(ctx context.Context, key string, opts EntityDetailsOptions) (*model.EntityDetails, error) {
+	// 按 ID 查询时只展开该节点，按名称查询时展开所有同名节点
+	starts := g.lookup(key)
+	if entity, ok := g.byKey[key]; ok {
+		starts = []*memoryEntity{entity}
+	}
+	if len(starts) == 0 {
+		return nil, nil
+	}
+
+	details := &model.EntityDetails{Entity: starts[0].node}
+
+	// 广度优先展开，关系的跳数为其靠近查询实体一端的深度加一
+	type tripleKey struct {
+		head, tail *memoryEntity
+		relType    string
+	}
+	seen := make(map[tripleKey]bool)
+	depth := make(map[*memoryEntity]int)
+	queue := make([]*memoryEntity, 0, len(starts))
+	for _, e := range starts {
+		depth[e] = 0
+		queue = append(queue, e)
+	}
+
+	for len(queue) > 0 && len(details.Relations) < opts.RelationLimit {
+		e := queue[0]
+		queue = queue[1:]
+		if depth[e] >= opts.Hops {
+			continue
+		}
+
+		for _, edge := range e.edges {
+			head, tail := e, edge.target
+			if !edge.outgoing {
+				head, tail = edge.target, e
+			}
+			k := tripleKey{head: head, tail: tail, relType: edge.relType}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+
+			details.Relations = append(details.Relations, model.Triple{
+				Head:    head.ref(),
+				Type:    edge.relType,
+				Tail:    tail.ref(),
+				Hop:     depth[e] + 1,
+				Weight:  edge.rel.weight(),
+				Sources: edge.rel.sources,
+			})
+			if len(details.Relations) >= opts.RelationLimit {
+				break
+			}
+
+			if _, ok := depth[edge.target]; !ok {
+				depth[edge.target] = depth[e] + 1
+				queue = append(queue, edge.target)
+			}
+		}
+	}
+
+	seenParagraphs := make(map[*memoryParagraph]bool)
+	for _, e := range starts {
+		for _, s := range e.mentions {
+			if len(details.Sentences) >= opts.EvidenceLimit {
+				break
+			}
+			details.Sentences = append(details.Sentences, model.EvidenceSentence{
+				DocID:       s.docID,
+				ParagraphID: s.paragraph.id,
+				SentenceID:  s.id,
+				Text:        s.text,
+			})
+			if !seenParagraphs[s.paragraph] {
+				seenParagraphs[s.paragraph] = true
+				details.Paragraphs = append(details.Paragraphs, model.EvidenceParagraph{
+					DocID:       s.docID,
+					ParagraphID: s.paragraph.id,
+					Text:        s.paragraph.text,
+				})
+			}
+		}
+	}
+
+	return details, nil
+}
+
+func (g *memoryKnowledgeGraph) FindPaths(ctx context.Context, from, to string, opts PathOptions) ([]model.Path, error) {
+	start, target := g.byKey[from], g.byKey[to]
+	if start == nil || target == nil || start == target {
+		return nil, nil
+	}
+
+	allowed := make(map[string]bool)
+	for _, t := range opts.RelationTypes {
+		allowed[t] = true
+	}
+	follow := func(edge memoryEdge) bool {
+		return len(allowed) == 0 || allowed[edge.relType]
+	}
+
+	// 各实体到终点的最短距离，不在其中的实体无法在 MaxLength 步内到达终点
+	dist := distancesTo(target, opts.MaxLength, follow)
+	if _, ok := dist[start]; !ok {
+		return nil, nil
+	}
+
+	var paths []model.Path
+	var err error
+
+	// 逐层加深，保证先得到较短的路径
+	for length := 1; length <= opts.MaxLength && len(paths) < opts.K && err == nil; length++ {
+		visited := map[*memoryEntity]bool{start: true}
+		nodes := []*memoryEntity{start}
+		var edges []memoryEdge
+
+		var walk func(e *memoryEntity)
+		walk = func(e *memoryEntity) {
+			if len(paths) >= opts.K || err != nil {
+				return
+			}
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			if len(edges) == length {
+				if e == target {
+					paths = append(paths, buildPath(nodes, edges))
+				}
+				return
+			}
+
+			for _, edge := range e.edges {
+				if visited[edge.target] || !follow(edge) {
+					continue
+				}
+				// 剩余步数不足以到达终点的分支不再展开
+				if d, ok := dist[edge.target]; !ok || len(edges)+1+d > length {
+					continue
+				}
+				// 路径中途不经过终点实体
+				if edge.target == target && len(edges)+1 < length {
+					continue
+				}
+				visited[edge.target] = true
+				nodes = append(nodes, edge.target)
+				edges = append(edges, edge)
+				walk(edge.target)
+				nodes = nodes[:len(nodes)-1]
+				edges = edges[:len(edges)-1]
+				visited[edge.target] = false
+			}
+		}
+		walk(start)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// distancesTo 从终点出发广度优先搜索，返回 maxLength 步内可达实体到终点的距离；关系双向存储，距离与方向无关
+func distancesTo(target *memoryEntity, maxLength int, follow func(memoryEdge) bool) map[*memoryEntity]int {
+	dist := map[*memoryEntity]int{target: 0}
+	frontier := []*memoryEntity{target}
+	for d := 1; d <= maxLength && len(frontier) > 0; d++ {
+		var next []*memoryEntity
+		for _, e := range frontier {
+			for _, edge := range e.edges {
+				if _, ok := dist[edge.target]; ok || !follow(edge) {
+					continue
+				}
+				dist[edge.target] = d
+				next = append(next, edge.target)
+			}
+		}
+		frontier = next
+	}
+	return dist
+}
+
+func (g *memoryKnowledgeGraph) SearchPassages(ctx context.Context, keywords []string, opts PassageOptions) ([]model.Passage, error) {
+	terms := normalizeTerms(keywords)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("valid keywords not found")
+	}
+
+	var queryVector []float32
+	if g.embedder != nil {
+		vectors, err := g.embedder.Embed(ctx, []string{queryText(keywords, opts.Query)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+		queryVector = vectors[0]
+	}
+
+	// 句子的向量相似度，段落取其中句子的最高值
+	similarity := func(s *memorySentence) float32 {
+		if queryVector == nil {
+			return 0
+		}
+		return embedding.Cosine(queryVector, s.vector)
+	}
+
+	var candidates []passageCandidate
+	add := func(c passageCandidate) {
+		if c.textScore == 0 && c.vectorScore < minVectorSimilarity {
+			return
+		}
+		candidates = append(candidates, c)
+	}
+
+	if opts.Level != model.PassageLevelParagraph {
+		for _, s := range g.sentences {
+			add(passageCandidate{
+				passage: model.Passage{
+					Level:       model.PassageLevelSentence,
+					DocID:       s.docID,
+					ParagraphID: s.paragraph.id,
+					SentenceID:  s.id,
+					Text:        s.text,
+					Entities:    refs(s.entities),
+				},
+				textScore:   passageScore(s.text, terms),
+				vectorScore: similarity(s),
+			})
+		}
+	}
+	if opts.Level != model.PassageLevelSentence {
+		for _, p := range g.paragraphs {
+			var entities []*memoryEntity
+			var vectorScore float32
+			for _, s := range p.sentences {
+				for _, e := range s.entities {
+					if !slices.Contains(entities, e) {
+						entities = append(entities, e)
+					}
+				}
+				vectorScore = max(vectorScore, similarity(s))
+			}
+			add(passageCandidate{
+				passage: model.Passage{
+					Level:       model.PassageLevelParagraph,
+					DocID:       p.docID,
+					ParagraphID: p.id,
+					Text:        p.text,
+					Entities:    refs(entities),
+				},
+				textScore:   passageScore(p.text, terms),
+				vectorScore: vectorScore,
+			})
+		}
+	}
+
+	if queryVector != nil {
+		return fusePassages(candidates, opts.Limit), nil
+	}
+
+	passages := make([]model.Passage, len(candidates))
+	for i, c := range candidates {
+		passages[i] = c.passage
+		passages[i].Score = c.textScore
+	}
+	sort.SliceStable(passages, func(i, j int) bool {
+		return passages[i].Score > passages[j].Score
+	})
+
+	return truncate(passages, opts.Limit), nil
+}
+
+func buildPath(nodes []*memoryEntity, edges []memoryEdge) model.Path {
+	path := model.Path{Length: len(edges)}
+	for _, n := range nodes {
+		path.Nodes = append(path.Nodes, n.ref())
+	}
+	for _, e := range edges {
+		path.Relations = append(path.Relations, model.PathRelation{Type: e.relType, Forward: e.outgoing})
+	}
+	return path
+}
+
+// 返回实体的直接关系，按权重降序，types 非空时只保留这些类型
+func (e *memoryEntity) relations(types []string) []model.Relation {
+	relations := make([]model.Relation, 0, len(e.edges))
+	for _, edge := range e.edges {
+		if len(types) > 0 && !slices.Contains(types, edge.relType) {
+			continue
+		}
+		relations = append(relations, model.Relation{
+			Type:    edge.relType,
+			Related: edge.target.ref(),
+			Weight:  edge.rel.weight(),
+			Sources: edge.rel.sources,
+		})
+	}
+	sort.SliceStable(relations, func(i, j int) bool {
+		return relations[i].Weight > relations[j].Weight
+	})
+	return relations
+}
+
+// ref 关系端点和路径中的实体，不附带别名，与 Neo4j 后端返回的字段一致
+func (e *memoryEntity) ref() model.EntityNode {
+	node := e.node
+	node.Aliases = nil
+	return node
+}
+
+func refs(entities []*memoryEntity) []model.EntityNode {
+	nodes := make([]model.EntityNode, len(entities))
+	for i, e := range entities {
+		nodes[i] = e.ref()
+	}
+	return nodes
+}
+
+// 归一化关键词，去掉归一化后为空的关键词
+func normalizeTerms(keywords []string) []string {
+	var terms []string
+	for _, k := range keywords {
+		if k = model.NormalizeEntityName(k); k != "" {
+			terms = append(terms, k)
+		}
+	}
+	return terms
+}
+
+// 近似全文索引对原文的相关度：命中的关键词按长度计分，再按文本长度的平方根衰减，较短的原文得分更高
+func passageScore(text string, terms []string) float32 {
+	lower := model.NormalizeEntityName(text)
+	textLen := utf8.RuneCountInString(lower)
+	if textLen == 0 {
+		return 0
+	}
+
+	var score float32
+	for _, t := range terms {
+		if strings.Contains(lower, t) {
+			score += float32(utf8.RuneCountInString(t))
+		}
+	}
+	return score / float32(math.Sqrt(float64(textLen)))
+}
+
+// 近似全文索引的相关度：每个命中的关键词按其覆盖名称的比例计分，完全匹配额外加分
+func matchScore(name string, terms []string) float32 {
+	lower := model.NormalizeEntityName(name)
+	nameLen := utf8.RuneCountInString(lower)
+	if nameLen == 0 {
+		return 0
+	}
+
+	var score float32
+	for _, t := range terms {
+		if !strings.Contains(lower, t) {
+			continue
+		}
+		score += float32(utf8.RuneCountInString(t)) / float32(nameLen)
+		if lower == t {
+			score += 1
+		}
+	}
+	return score
+}
@@ -0,0 +1,373 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/model"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newFixtureGraph 构建测试用的小图谱：
+//
+//	乳酸酸中毒 -ADE_Drug-> 二甲双胍 -Drug_Disease-> 2型糖尿病 <-Drug_Disease- 胰岛素 <-ADE_Drug- 低血糖
+//	低血糖 -ADE_Drug-> 二甲双胍，多饮 -Symptom_Disease-> 2型糖尿病，肾穿刺 -Test_Disease-> 糖尿病肾病
+//
+// 二甲双胍与 2型糖尿病 的关系同时出现在文档标注和三元组中，权重为 2
+func newFixtureGraph() *memoryKnowledgeGraph {
+	g := newMemoryKnowledgeGraph()
+	g.AddDocument(&model.DiaKGDocument{
+		DocID: "doc1",
+		Paragraphs: []model.DiaKGParagraph{{
+			ParagraphID: "p1",
+			Paragraph:   "二甲双胍是2型糖尿病的一线用药。",
+			Sentences: []model.DiaKGSentence{{
+				SentenceID: "s1",
+				Sentence:   "二甲双胍是2型糖尿病的一线用药。",
+				Entities: []model.DiaKGEntity{
+					{EntityID: "T1", Entity: "二甲双胍", EntityType: "Drug"},
+					{EntityID: "T2", Entity: "2型糖尿病", EntityType: "Disease"},
+				},
+				Relations: []model.DiaKGRelation{
+					{RelationType: "Drug_Disease", RelationID: "R1", HeadEntityID: "T1", TailEntityID: "T2"},
+				},
+			}},
+		}},
+	})
+	g.AddTriples([]model.KGTriple{
+		{Head: "二甲双胍", HeadType: "Drug", Relation: "Drug_Disease", Tail: "2型糖尿病", TailType: "Disease"},
+		{Head: "胰岛素", HeadType: "Drug", Relation: "Drug_Disease", Tail: "2型糖尿病", TailType: "Disease"},
+		{Head: "乳酸酸中毒", HeadType: "ADE", Relation: "ADE_Drug", Tail: "二甲双胍", TailType: "Drug"},
+		{Head: "低血糖", HeadType: "ADE", Relation: "ADE_Drug", Tail: "胰岛素", TailType: "Drug"},
+		{Head: "低血糖", HeadType: "ADE", Relation: "ADE_Drug", Tail: "二甲双胍", TailType: "Drug"},
+		{Head: "多饮", HeadType: "Symptom", Relation: "Symptom_Disease", Tail: "2型糖尿病", TailType: "Disease"},
+		{Head: "肾穿刺", HeadType: "Test", Relation: "Test_Disease", Tail: "糖尿病肾病", TailType: "Disease"},
+		// 不合法的三元组被忽略
+		{Head: "二甲双胍", HeadType: "Drug", Relation: "Unknown", Tail: "多饮", TailType: "Symptom"},
+	}, "triples")
+	return g
+}
+
+func TestMemorySearchEntities(t *testing.T) {
+	g := newFixtureGraph()
+
+	tests := []struct {
+		name     string
+		keywords []string
+		opts     SearchOptions
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "exact match ranks first",
+			keywords: []string{"糖尿病", "2型糖尿病"},
+			want:     []string{"2型糖尿病", "糖尿病肾病"},
+		},
+		{
+			name:     "shorter name covers more",
+			keywords: []string{"糖尿病肾"},
+			want:     []string{"糖尿病肾病"},
+		},
+		{
+			name:     "limit",
+			keywords: []string{"糖尿病", "2型糖尿病"},
+			opts:     SearchOptions{Limit: 1},
+			want:     []string{"2型糖尿病"},
+		},
+		{
+			name:     "entity types",
+			keywords: []string{"二甲双胍", "低血糖"},
+			opts:     SearchOptions{EntityTypes: []string{"ADE"}},
+			want:     []string{"低血糖"},
+		},
+		{
+			name:     "relation types",
+			keywords: []string{"糖尿病"},
+			opts:     SearchOptions{RelationTypes: []string{"Test_Disease"}},
+			want:     []string{"糖尿病肾病"},
+		},
+		{
+			name:     "no match",
+			keywords: []string{"高血压"},
+			want:     nil,
+		},
+		{
+			name:     "blank keywords",
+			keywords: []string{" ", ""},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := g.SearchEntities(context.Background(), tt.keywords, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchEntities error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, r := range results {
+				names = append(names, r.Node.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("names = %v, want %v", names, tt.want)
+			}
+			for i := 1; i < len(results); i++ {
+				if results[i].Score > results[i-1].Score {
+					t.Errorf("results not sorted by score: %v", results)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryGetNeighbours(t *testing.T) {
+	g := newFixtureGraph()
+
+	tests := []struct {
+		name   string
+		entity string
+		limit  int
+		want   []string
+	}{
+		{"sorted by weight", "2型糖尿病", 0, []string{"Drug_Disease 二甲双胍 2", "Drug_Disease 胰岛素 1", "Symptom_Disease 多饮 1"}},
+		{"limit", "2型糖尿病", 1, []string{"Drug_Disease 二甲双胍 2"}},
+		{"name is normalized", " 2型糖尿病 ", 1, []string{"Drug_Disease 二甲双胍 2"}},
+		{"unknown", "高血压", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relations, err := g.GetNeighbours(context.Background(), tt.entity, tt.limit)
+			if err != nil {
+				t.Fatalf("GetNeighbours: %v", err)
+			}
+			var got []string
+			for _, r := range relations {
+				got = append(got, fmt.Sprintf("%s %s %d", r.Type, r.Related.Name, r.Weight))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("relations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryGetEntityDetails(t *testing.T) {
+	g := newFixtureGraph()
+
+	tests := []struct {
+		name string
+		key  string
+		opts EntityDetailsOptions
+		// want 关系及其跳数，格式为 头实体-关系->尾实体@跳数
+		want      []string
+		sentences int
+	}{
+		{
+			name: "one hop",
+			key:  "Drug:二甲双胍",
+			opts: EntityDetailsOptions{Hops: 1, RelationLimit: 10, EvidenceLimit: 10},
+			want: []string{
+				"二甲双胍-Drug_Disease->2型糖尿病@1",
+				"乳酸酸中毒-ADE_Drug->二甲双胍@1",
+				"低血糖-ADE_Drug->二甲双胍@1",
+			},
+			sentences: 1,
+		},
+		{
+			name: "two hops expand breadth first without repeating relations",
+			key:  "Drug:二甲双胍",
+			opts: EntityDetailsOptions{Hops: 2, RelationLimit: 10, EvidenceLimit: 10},
+			want: []string{
+				"二甲双胍-Drug_Disease->2型糖尿病@1",
+				"乳酸酸中毒-ADE_Drug->二甲双胍@1",
+				"低血糖-ADE_Drug->二甲双胍@1",
+				"胰岛素-Drug_Disease->2型糖尿病@2",
+				"多饮-Symptom_Disease->2型糖尿病@2",
+				"低血糖-ADE_Drug->胰岛素@2",
+			},
+			sentences: 1,
+		},
+		{
+			name: "relation limit",
+			key:  "Drug:二甲双胍",
+			opts: EntityDetailsOptions{Hops: 2, RelationLimit: 2, EvidenceLimit: 0},
+			want: []string{
+				"二甲双胍-Drug_Disease->2型糖尿病@1",
+				"乳酸酸中毒-ADE_Drug->二甲双胍@1",
+			},
+		},
+		{
+			name: "lookup by name",
+			key:  "肾穿刺",
+			opts: EntityDetailsOptions{Hops: 3, RelationLimit: 10, EvidenceLimit: 10},
+			want: []string{"肾穿刺-Test_Disease->糖尿病肾病@1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, err := g.GetEntityDetails(context.Background(), tt.key, tt.opts)
+			if err != nil || details == nil {
+				t.Fatalf("GetEntityDetails = %v, %v", details, err)
+			}
+			var got []string
+			for _, r := range details.Relations {
+				got = append(got, fmt.Sprintf("%s-%s->%s@%d", r.Head.Name, r.Type, r.Tail.Name, r.Hop))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("relations = %v, want %v", got, tt.want)
+			}
+			if len(details.Sentences) != tt.sentences {
+				t.Errorf("sentences = %d, want %d", len(details.Sentences), tt.sentences)
+			}
+		})
+	}
+
+	details, err := g.GetEntityDetails(context.Background(), "高血压", EntityDetailsOptions{Hops: 1, RelationLimit: 10})
+	if err != nil || details != nil {
+		t.Errorf("GetEntityDetails unknown = %v, %v; want nil, nil", details, err)
+	}
+}
+
+func TestMemoryFindPaths(t *testing.T) {
+	g := newFixtureGraph()
+
+	tests := []struct {
+		name     string
+		from, to string
+		opts     PathOptions
+		want     []string
+	}{
+		{
+			name: "shorter paths first",
+			from: "ADE:低血糖", to: "Drug:胰岛素",
+			opts: PathOptions{MaxLength: 3, K: 5},
+			want: []string{
+				"低血糖 -ADE_Drug-> 胰岛素",
+				"低血糖 -ADE_Drug-> 二甲双胍 -Drug_Disease-> 2型糖尿病 <-Drug_Disease- 胰岛素",
+			},
+		},
+		{
+			name: "k",
+			from: "ADE:低血糖", to: "Drug:胰岛素",
+			opts: PathOptions{MaxLength: 3, K: 1},
+			want: []string{"低血糖 -ADE_Drug-> 胰岛素"},
+		},
+		{
+			name: "max length",
+			from: "ADE:乳酸酸中毒", to: "Drug:胰岛素",
+			opts: PathOptions{MaxLength: 2, K: 5},
+			want: nil,
+		},
+		{
+			name: "does not pass through target",
+			from: "Drug:二甲双胍", to: "Disease:2型糖尿病",
+			opts: PathOptions{MaxLength: 3, K: 5},
+			want: []string{
+				"二甲双胍 -Drug_Disease-> 2型糖尿病",
+				"二甲双胍 <-ADE_Drug- 低血糖 -ADE_Drug-> 胰岛素 -Drug_Disease-> 2型糖尿病",
+			},
+		},
+		{
+			name: "relation types",
+			from: "ADE:乳酸酸中毒", to: "Drug:胰岛素",
+			opts: PathOptions{MaxLength: 4, K: 5, RelationTypes: []string{"ADE_Drug"}},
+			want: []string{"乳酸酸中毒 -ADE_Drug-> 二甲双胍 <-ADE_Drug- 低血糖 -ADE_Drug-> 胰岛素"},
+		},
+		{
+			name: "unknown key",
+			from: "低血糖", to: "Drug:胰岛素",
+			opts: PathOptions{MaxLength: 4, K: 5},
+			want: nil,
+		},
+		{
+			name: "same entity",
+			from: "Drug:胰岛素", to: "Drug:胰岛素",
+			opts: PathOptions{MaxLength: 4, K: 5},
+			want: nil,
+		},
+		{
+			name: "disconnected",
+			from: "Test:肾穿刺", to: "Drug:胰岛素",
+			opts: PathOptions{MaxLength: 4, K: 5},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := g.FindPaths(context.Background(), tt.from, tt.to, tt.opts)
+			if err != nil {
+				t.Fatalf("FindPaths: %v", err)
+			}
+			var got []string
+			for _, p := range paths {
+				if p.Length != len(p.Relations) || len(p.Nodes) != p.Length+1 {
+					t.Errorf("inconsistent path %+v", p)
+				}
+				got = append(got, pathString(p))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("paths = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryFindPathsPrunesHubs(t *testing.T) {
+	// 起点经两个高度数的中心实体连向大量实体，终点只能经另一条链在 4 步内到达；
+	// 不剪枝时长度为 4 的搜索需要遍历约 hubDegree² 条部分路径
+	const hubDegree = 1000
+	g := newMemoryKnowledgeGraph()
+	triples := []model.KGTriple{
+		{Head: "二甲双胍", HeadType: "Drug", Relation: "Drug_Disease", Tail: "2型糖尿病", TailType: "Disease"},
+		{Head: "二甲双胍", HeadType: "Drug", Relation: "Drug_Disease", Tail: "多囊卵巢综合征", TailType: "Disease"},
+		{Head: "胃肠道反应", HeadType: "ADE", Relation: "ADE_Drug", Tail: "二甲双胍", TailType: "Drug"},
+		{Head: "胃肠道反应", HeadType: "ADE", Relation: "ADE_Drug", Tail: "阿卡波糖", TailType: "Drug"},
+		{Head: "阿卡波糖", HeadType: "Drug", Relation: "Drug_Disease", Tail: "糖耐量异常", TailType: "Disease"},
+	}
+	for i := range hubDegree {
+		drug := fmt.Sprintf("药物%d", i)
+		triples = append(triples,
+			model.KGTriple{Head: drug, HeadType: "Drug", Relation: "Drug_Disease", Tail: "2型糖尿病", TailType: "Disease"},
+			model.KGTriple{Head: drug, HeadType: "Drug", Relation: "Drug_Disease", Tail: "糖尿病肾病", TailType: "Disease"},
+		)
+	}
+	g.AddTriples(triples, "triples")
+
+	paths, err := g.FindPaths(context.Background(), "Disease:多囊卵巢综合征", "Disease:糖耐量异常", PathOptions{MaxLength: 5, K: 5})
+	if err != nil {
+		t.Fatalf("FindPaths: %v", err)
+	}
+	var got []string
+	for _, p := range paths {
+		got = append(got, pathString(p))
+	}
+	want := []string{"多囊卵巢综合征 <-Drug_Disease- 二甲双胍 <-ADE_Drug- 胃肠道反应 -ADE_Drug-> 阿卡波糖 -Drug_Disease-> 糖耐量异常"}
+	if !slices.Equal(got, want) {
+		t.Errorf("paths = %q, want %q", got, want)
+	}
+}
+
+func TestMemoryFindPathsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := newFixtureGraph().FindPaths(ctx, "ADE:低血糖", "Drug:胰岛素", PathOptions{MaxLength: 3, K: 5})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FindPaths error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func pathString(p model.Path) string {
+	var b strings.Builder
+	b.WriteString(p.Nodes[0].Name)
+	for i, r := range p.Relations {
+		if r.Forward {
+			fmt.Fprintf(&b, " -%s-> ", r.Type)
+		} else {
+			fmt.Fprintf(&b, " <-%s- ", r.Type)
+		}
+		b.WriteString(p.Nodes[i+1].Name)
+	}
+	return b.String()
+}
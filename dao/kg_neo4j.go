@@ -0,0 +1,681 @@
+package dao
+
+import (
+	"context"
+	"diabetes-care-mcp-server/config"
+	"diabetes-care-mcp-server/embedding"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	Neo4jFulltextIndexName = "fulltext_index_entity_name"
+	// 句子和段落原文上的全文索引，由导入工具创建
+	Neo4jPassageIndexName = "fulltext_index_passage_text"
+	// 实体名称和句子向量上的向量索引，由导入工具创建
+	Neo4jEntityVectorIndexName   = "vector_index_entity_embedding"
+	Neo4jSentenceVectorIndexName = "vector_index_sentence_embedding"
+
+	neo4jConnectTimeout = 10 * time.Second
+)
+
+type neo4jKnowledgeGraph struct {
+	driver   neo4j.DriverWithContext
+	embedder embedding.Embedder
+	// vectorDimensions 实体向量索引的维度，为 0 表示索引不存在，只做全文检索
+	vectorDimensions int
+	// sentenceVectors 句子向量索引存在且与实体向量索引维度一致，原文检索可按向量召回句子
+	sentenceVectors bool
+}
+
+// NewNeo4jKnowledgeGraph 连接 Neo4j 并校验连通性，embedder 不为空且实体向量索引存在时启用混合检索
+func NewNeo4jKnowledgeGraph(ctx context.Context, dbConfig config.DBConfig, embedder embedding.Embedder) (KnowledgeGraph, error) {
+	dsn := fmt.Sprintf("neo4j://%s:%s", dbConfig.Host, dbConfig.Port)
+
+	driver, err := neo4j.NewDriverWithContext(
+		dsn,
+		neo4j.BasicAuth(dbConfig.Username, dbConfig.Password, ""),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Neo4j driver: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, neo4jConnectTimeout)
+	defer cancel()
+
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		driver.Close(ctx)
+		return nil, fmt.Errorf("failed to connect to Neo4j server: %w", err)
+	}
+
+	g := &neo4jKnowledgeGraph{driver: driver}
+	if embedder != nil {
+		dimensions, err := g.vectorIndexDimensions(ctx, Neo4jEntityVectorIndexName)
+		if err != nil {
+			driver.Close(ctx)
+			return nil, err
+		}
+		if dimensions == 0 {
+			slog.Warn("Entity vector index not found, semantic search disabled", "index", Neo4jEntityVectorIndexName)
+		} else {
+			g.embedder, g.vectorDimensions = embedder, dimensions
+		}
+	}
+	if g.embedder != nil {
+		dimensions, err := g.vectorIndexDimensions(ctx, Neo4jSentenceVectorIndexName)
+		if err != nil {
+			driver.Close(ctx)
+			return nil, err
+		}
+		g.sentenceVectors = dimensions == g.vectorDimensions
+		if !g.sentenceVectors {
+			slog.Warn("Sentence vector index not found or mismatched, passages are searched by fulltext only",
+				"index", Neo4jSentenceVectorIndexName,
+				"dimensions", dimensions,
+			)
+		}
+	}
+
+	return g, nil
+}
+
+// vectorIndexDimensions 返回向量索引的维度，索引不存在时返回 0
+func (g *neo4jKnowledgeGraph) vectorIndexDimensions(ctx context.Context, name string) (int, error) {
+	cypherQuery := `
+        SHOW VECTOR INDEXES
+        YIELD name, options
+        WHERE name = $name
+        RETURN options.indexConfig['vector.dimensions'] AS dimensions
+    `
+
+	var dimensions int
+	err := g.read(ctx, cypherQuery, map[string]any{"name": name}, func(record map[string]any) error {
+		d, _ := record["dimensions"].(int64)
+		dimensions = int(d)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to check vector index: %w", err)
+	}
+	return dimensions, nil
+}
+
+func (g *neo4jKnowledgeGraph) Close(ctx context.Context) error {
+	return g.driver.Close(ctx)
+}
+
+// SearchEntities 执行全文搜索，根据 keywords 模糊匹配 Entity 节点的 name 和别名；
+// 启用混合检索时同时按查询向量召回实体，融合两者的得分
+func (g *neo4jKnowledgeGraph) SearchEntities(ctx context.Context, keywords []string, opts SearchOptions) ([]model.KnowlegeGraphSearchResult, error) {
+	terms := normalizeTerms(keywords)
+
+	keywords = escapeKeywords(keywords)
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("valid keywords not found")
+	}
+
+	// 构建模糊查询条件
+	query := strings.Join(keywords, " OR ")
+
+	if g.embedder != nil {
+		vectors, err := g.embedder.Embed(ctx, []string{queryText(keywords, opts.Query)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+		if len(vectors[0]) == g.vectorDimensions {
+			return g.hybridSearch(ctx, query, vectors[0], terms, opts)
+		}
+		slog.Warn("Query vector does not match vector index, falling back to fulltext search",
+			"dimensions", len(vectors[0]),
+			"index_dimensions", g.vectorDimensions,
+		)
+	}
+
+	// 返回匹配查询且至少存在一个符合条件关系的节点
+	cypherQuery := `
+        CALL db.index.fulltext.queryNodes($indexName, $query) 
+        YIELD node, score
+        WHERE 'Entity' IN labels(node)
+          AND (size($entityTypes) = 0 OR node.type IN $entityTypes)
+        MATCH (node)-[r]-(related:Entity)
+        WHERE size($relationTypes) = 0 OR type(r) IN $relationTypes
+        WITH node, score, r, related
+        ORDER BY r.weight DESC
+        WITH node, score, collect({
+            type: type(r),
+            related: related {.name, .type, .sources, id: related.key},
+            weight: r.weight,
+            sources: r.sources
+        }) AS relationships
+        RETURN 
+            node {.name, .type, .sources, .aliases, id: node.key} AS node,
+            relationships,
+            score
+        ORDER BY score DESC
+        LIMIT $limit
+    `
+
+	var results []model.KnowlegeGraphSearchResult
+	err := g.read(ctx, cypherQuery, map[string]any{
+		"indexName":     Neo4jFulltextIndexName,
+		"query":         query,
+		"entityTypes":   nonNil(opts.EntityTypes),
+		"relationTypes": nonNil(opts.RelationTypes),
+		"limit":         opts.Limit,
+	}, func(record map[string]any) error {
+		var sr model.KnowlegeGraphSearchResult
+		if err := mapstructure.Decode(record, &sr); err != nil {
+			return fmt.Errorf("failed to decode search result: %v", err)
+		}
+		sr.MatchedAlias = matchedAlias(sr.Node, terms)
+		results = append(results, sr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// hybridSearch 合并全文检索和向量检索召回的实体，按融合得分排序
+func (g *neo4jKnowledgeGraph) hybridSearch(ctx context.Context, query string, vector []float32, terms []string, opts SearchOptions) ([]model.KnowlegeGraphSearchResult, error) {
+	// 余弦相似度索引返回的得分为 (1 + cos) / 2，换算回余弦相似度后与本地实现保持一致
+	cypherQuery := `
+        CALL {
+            CALL db.index.fulltext.queryNodes($indexName, $query)
+            YIELD node, score
+            WHERE 'Entity' IN labels(node)
+              AND (size($entityTypes) = 0 OR node.type IN $entityTypes)
+            RETURN node, score AS textScore, 0.0 AS vectorScore
+            LIMIT $candidates
+          UNION ALL
+            CALL db.index.vector.queryNodes($vectorIndexName, $candidates, $vector)
+            YIELD node, score
+            WITH node, 2 * score - 1 AS similarity
+            WHERE node.embedding_model = $embeddingModel
+              AND similarity >= $minSimilarity
+              AND (size($entityTypes) = 0 OR node.type IN $entityTypes)
+            RETURN node, 0.0 AS textScore, similarity AS vectorScore
+        }
+        WITH node, max(textScore) AS textScore, max(vectorScore) AS vectorScore
+        MATCH (node)-[r]-(related:Entity)
+        WHERE size($relationTypes) = 0 OR type(r) IN $relationTypes
+        WITH node, textScore, vectorScore, r, related
+        ORDER BY r.weight DESC
+        WITH node, textScore, vectorScore, collect({
+            type: type(r),
+            related: related {.name, .type, .sources, id: related.key},
+            weight: r.weight,
+            sources: r.sources
+        }) AS relationships
+        RETURN
+            node {.name, .type, .sources, .aliases, id: node.key} AS node,
+            relationships,
+            textScore,
+            vectorScore
+    `
+
+	var candidates []searchCandidate
+	err := g.read(ctx, cypherQuery, map[string]any{
+		"indexName":       Neo4jFulltextIndexName,
+		"vectorIndexName": Neo4jEntityVectorIndexName,
+		"query":           query,
+		"vector":          vector,
+		"embeddingModel":  g.embedder.Model(),
+		"minSimilarity":   minVectorSimilarity,
+		"candidates":      opts.Limit * vectorCandidateFactor,
+		"entityTypes":     nonNil(opts.EntityTypes),
+		"relationTypes":   nonNil(opts.RelationTypes),
+	}, func(record map[string]any) error {
+		var c searchCandidate
+		if err := mapstructure.Decode(record, &c.result); err != nil {
+			return fmt.Errorf("failed to decode search result: %v", err)
+		}
+		textScore, _ := record["textScore"].(float64)
+		vectorScore, _ := record["vectorScore"].(float64)
+		c.textScore, c.vectorScore = float32(textScore), float32(vectorScore)
+		c.result.MatchedAlias = matchedAlias(c.result.Node, terms)
+		candidates = append(candidates, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fuseScores(candidates, opts.Limit), nil
+}
+
+func (g *neo4jKnowledgeGraph) GetEntity(ctx context.Context, name string) (*model.EntityNode, error) {
+	cypherQuery := `
+        MATCH (n:Entity)
+        WHERE n.normalized_name = $name OR $name IN n.normalized_aliases
+        RETURN n {.name, .type, .sources, .aliases, id: n.key} AS node
+        ORDER BY n.normalized_name = $name DESC
+        LIMIT 1
+    `
+
+	var entity *model.EntityNode
+	err := g.read(ctx, cypherQuery, map[string]any{"name": model.NormalizeEntityName(name)}, func(record map[string]any) error {
+		var node model.EntityNode
+		if err := mapstructure.Decode(record["node"], &node); err != nil {
+			return fmt.Errorf("failed to decode entity: %v", err)
+		}
+		entity = &node
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+func (g *neo4jKnowledgeGraph) ListEntities(ctx context.Context) ([]model.EntityNode, error) {
+	cypherQuery := `
+        MATCH (n:Entity)
+        RETURN n {.name, .type, .sources, .aliases, id: n.key} AS node
+    `
+
+	var entities []model.EntityNode
+	err := g.read(ctx, cypherQuery, nil, func(record map[string]any) error {
+		var node model.EntityNode
+		if err := mapstructure.Decode(record["node"], &node); err != nil {
+			return fmt.Errorf("failed to decode entity: %v", err)
+		}
+		entities = append(entities, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+func (g *neo4jKnowledgeGraph) GetNeighbours(ctx context.Context, name string, limit int) ([]model.Relation, error) {
+	cypherQuery := `
+        MATCH (n:Entity {normalized_name: $name})-[r]-(related:Entity)
+        RETURN type(r) AS type, related {.name, .type, .sources, id: related.key} AS related, r.weight AS weight, r.sources AS sources
+        ORDER BY weight DESC
+        LIMIT $limit
+    `
+
+	var relations []model.Relation
+	err := g.read(ctx, cypherQuery, map[string]any{
+		"name":  model.NormalizeEntityName(name),
+		"limit": limit,
+	}, func(record map[string]any) error {
+		var rel model.Relation
+		if err := mapstructure.Decode(record, &rel); err != nil {
+			return fmt.Errorf("failed to decode relation: %v", err)
+		}
+		relations = append(relations, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return relations, nil
+}
+
+func (g *neo4jKnowledgeGraph) GetEntityDetails(ctx context.Context, key string, opts EntityDetailsOptions) (*model.EntityDetails, error) {
+	entityQuery := `
+        MATCH (n:Entity)
+        WHERE n.key = $key OR n.normalized_name = $name
+        RETURN n {.name, .type, .sources, .aliases, id: n.key} AS node
+        ORDER BY n.key = $key DESC
+        LIMIT 1
+    `
+
+	name := model.NormalizeEntityName(key)
+	var details *model.EntityDetails
+	err := g.read(ctx, entityQuery, map[string]any{"key": key, "name": name}, func(record map[string]any) error {
+		details = &model.EntityDetails{}
+		return mapstructure.Decode(record["node"], &details.Entity)
+	})
+	if err != nil || details == nil {
+		return nil, err
+	}
+
+	// 按 ID 查询时只展开该节点，按名称查询时展开所有同名节点
+	startQuery := `
+        MATCH (n:Entity)
+        WHERE n.key = $key OR (n.normalized_name = $name AND NOT EXISTS { MATCH (m:Entity {key: $key}) })
+        RETURN n.key AS key
+    `
+
+	var frontier []string
+	err = g.read(ctx, startQuery, map[string]any{"key": key, "name": name}, func(record map[string]any) error {
+		k, _ := record["key"].(string)
+		frontier = append(frontier, k)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 逐跳展开，每跳只从上一跳新到达的实体出发并按剩余条数限制返回的关系，不枚举多跳路径；
+	// 与之前已展开的实体之间的关系已在更早的跳中返回，不再重复
+	hopQuery := `
+        MATCH (n:Entity)
+        WHERE n.key IN $frontier
+        MATCH (n)-[r]-(m:Entity)
+        WHERE NOT m.key IN $expanded
+        WITH DISTINCT r, startNode(r) AS head, endNode(r) AS tail
+        RETURN
+            head {.name, .type, .sources, id: head.key} AS head,
+            type(r) AS type,
+            tail {.name, .type, .sources, id: tail.key} AS tail,
+            r.weight AS weight,
+            r.sources AS sources
+        ORDER BY weight DESC
+        LIMIT $limit
+    `
+
+	visited := make(map[string]bool)
+	for _, k := range frontier {
+		visited[k] = true
+	}
+	expanded := []string{}
+	for hop := 1; hop <= opts.Hops && len(frontier) > 0 && len(details.Relations) < opts.RelationLimit; hop++ {
+		var next []string
+		err = g.read(ctx, hopQuery, map[string]any{
+			"frontier": frontier,
+			"expanded": expanded,
+			"limit":    opts.RelationLimit - len(details.Relations),
+		}, func(record map[string]any) error {
+			var triple model.Triple
+			if err := mapstructure.Decode(record, &triple); err != nil {
+				return fmt.Errorf("failed to decode relation: %v", err)
+			}
+			triple.Hop = hop
+			details.Relations = append(details.Relations, triple)
+			for _, n := range []model.EntityNode{triple.Head, triple.Tail} {
+				if !visited[n.ID] {
+					visited[n.ID] = true
+					next = append(next, n.ID)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, frontier...)
+		frontier = next
+	}
+
+	evidenceQuery := `
+        MATCH (n:Entity)
+        WHERE n.key = $key OR (n.normalized_name = $name AND NOT EXISTS { MATCH (m:Entity {key: $key}) })
+        MATCH (d:Document)-[:CONTAINS_PARAGRAPH]->(p:Paragraph)-[:CONTAINS_SENTENCE]->(s:Sentence)-[:CONTAINS_ENTITY]->(n)
+        RETURN DISTINCT
+            d.doc_id AS doc_id,
+            p.paragraph_id AS paragraph_id,
+            p.text AS paragraph,
+            s.sentence_id AS sentence_id,
+            s.text AS sentence
+        LIMIT $limit
+    `
+
+	seenParagraphs := make(map[string]bool)
+	err = g.read(ctx, evidenceQuery, map[string]any{
+		"key":   key,
+		"name":  name,
+		"limit": opts.EvidenceLimit,
+	}, func(record map[string]any) error {
+		docID, _ := record["doc_id"].(string)
+		paraID, _ := record["paragraph_id"].(string)
+		sentenceID, _ := record["sentence_id"].(string)
+		sentence, _ := record["sentence"].(string)
+		paragraph, _ := record["paragraph"].(string)
+
+		details.Sentences = append(details.Sentences, model.EvidenceSentence{
+			DocID:       docID,
+			ParagraphID: paraID,
+			SentenceID:  sentenceID,
+			Text:        sentence,
+		})
+		if !seenParagraphs[paraID] {
+			seenParagraphs[paraID] = true
+			details.Paragraphs = append(details.Paragraphs, model.EvidenceParagraph{
+				DocID:       docID,
+				ParagraphID: paraID,
+				Text:        paragraph,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// FindPaths 以 allShortestPaths 查找最短的 K 条路径，只返回长度最短的路径，
+// 避免变长匹配在路径不足 K 条时枚举 MaxLength 以内的全部路径
+func (g *neo4jKnowledgeGraph) FindPaths(ctx context.Context, from, to string, opts PathOptions) ([]model.Path, error) {
+	types, err := relationTypePattern(opts.RelationTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// 关系类型和路径长度无法参数化，类型已校验，MaxLength 已由调用方限定为小整数；
+	// 只经过实体间的关系类型，路径不会经过句子和段落节点
+	cypherQuery := fmt.Sprintf(`
+        MATCH (a:Entity {key: $from}), (b:Entity {key: $to})
+        WHERE a <> b
+        MATCH p = allShortestPaths((a)-[:%s*..%d]-(b))
+        RETURN
+            [n IN nodes(p) | n {.name, .type, .sources, id: n.key}] AS nodes,
+            [i IN range(0, length(p) - 1) | {
+                type: type(relationships(p)[i]),
+                forward: startNode(relationships(p)[i]) = nodes(p)[i]
+            }] AS relations,
+            length(p) AS length
+        LIMIT $k
+    `, types, opts.MaxLength)
+
+	var paths []model.Path
+	err = g.read(ctx, cypherQuery, map[string]any{
+		"from": from,
+		"to":   to,
+		"k":    opts.K,
+	}, func(record map[string]any) error {
+		var path model.Path
+		if err := mapstructure.Decode(record, &path); err != nil {
+			return fmt.Errorf("failed to decode path: %v", err)
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// relationTypePattern 将关系类型拼接为模式中的类型过滤，为空时使用标注体系中的全部关系类型
+func relationTypePattern(types []string) (string, error) {
+	if len(types) == 0 {
+		types = model.DiaKGRelationTypes
+	}
+	for _, t := range types {
+		// 关系类型会拼接进查询语句，只允许标注体系中的类型，防止注入
+		if !model.IsDiaKGRelationType(t) {
+			return "", fmt.Errorf("invalid relation type %q", t)
+		}
+	}
+	return strings.Join(types, "|"), nil
+}
+
+// SearchPassages 在句子和段落原文的全文索引上检索，启用混合检索时同时按查询向量召回句子，
+// 命中句子所在的段落取该句的相似度
+func (g *neo4jKnowledgeGraph) SearchPassages(ctx context.Context, keywords []string, opts PassageOptions) ([]model.Passage, error) {
+	escaped := escapeKeywords(keywords)
+	if len(escaped) == 0 {
+		return nil, fmt.Errorf("valid keywords not found")
+	}
+
+	params := map[string]any{
+		"indexName":  Neo4jPassageIndexName,
+		"query":      strings.Join(escaped, " OR "),
+		"level":      opts.Level,
+		"candidates": opts.Limit * vectorCandidateFactor,
+	}
+
+	// 未启用向量检索时向量分支不召回任何节点
+	vectorBranch := ""
+	if g.sentenceVectors {
+		vectors, err := g.embedder.Embed(ctx, []string{queryText(keywords, opts.Query)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+		if len(vectors[0]) == g.vectorDimensions {
+			params["vectorIndexName"] = Neo4jSentenceVectorIndexName
+			params["vector"] = vectors[0]
+			params["embeddingModel"] = g.embedder.Model()
+			params["minSimilarity"] = minVectorSimilarity
+			vectorBranch = `
+          UNION ALL
+            CALL db.index.vector.queryNodes($vectorIndexName, $candidates, $vector)
+            YIELD node AS s, score
+            WITH s, 2 * score - 1 AS similarity
+            WHERE s.embedding_model = $embeddingModel AND similarity >= $minSimilarity
+            MATCH (p:Paragraph)-[:CONTAINS_SENTENCE]->(s)
+            UNWIND [s, p] AS node
+            WITH node, similarity
+            WHERE (node:Sentence AND $level <> 'paragraph') OR (node:Paragraph AND $level <> 'sentence')
+            RETURN node, 0.0 AS textScore, similarity AS vectorScore`
+		}
+	}
+
+	// 段落的实体为其所有句子中标注的实体
+	cypherQuery := fmt.Sprintf(`
+        CALL {
+            CALL db.index.fulltext.queryNodes($indexName, $query)
+            YIELD node, score
+            WHERE (node:Sentence AND $level <> 'paragraph') OR (node:Paragraph AND $level <> 'sentence')
+            RETURN node, score AS textScore, 0.0 AS vectorScore
+            LIMIT $candidates%s
+        }
+        WITH node, max(textScore) AS textScore, max(vectorScore) AS vectorScore
+        OPTIONAL MATCH (parent:Paragraph)-[:CONTAINS_SENTENCE]->(node)
+        WITH node, textScore, vectorScore, coalesce(parent, node) AS p
+        MATCH (d:Document)-[:CONTAINS_PARAGRAPH]->(p)
+        OPTIONAL MATCH (p)-[:CONTAINS_SENTENCE]->(s:Sentence)-[:CONTAINS_ENTITY]->(e:Entity)
+        WHERE node:Paragraph OR s = node
+        WITH node, textScore, vectorScore, p, d, collect(DISTINCT e {.name, .type, .sources, id: e.key}) AS entities
+        RETURN
+            CASE WHEN node:Sentence THEN 'sentence' ELSE 'paragraph' END AS level,
+            d.doc_id AS doc_id,
+            p.paragraph_id AS paragraph_id,
+            CASE WHEN node:Sentence THEN node.sentence_id END AS sentence_id,
+            node.text AS text,
+            entities,
+            textScore,
+            vectorScore
+    `, vectorBranch)
+
+	var candidates []passageCandidate
+	err := g.read(ctx, cypherQuery, params, func(record map[string]any) error {
+		var c passageCandidate
+		c.passage.Level, _ = record["level"].(string)
+		c.passage.DocID, _ = record["doc_id"].(string)
+		c.passage.ParagraphID, _ = record["paragraph_id"].(string)
+		c.passage.SentenceID, _ = record["sentence_id"].(string)
+		c.passage.Text, _ = record["text"].(string)
+		if err := mapstructure.Decode(record["entities"], &c.passage.Entities); err != nil {
+			return fmt.Errorf("failed to decode passage entities: %v", err)
+		}
+		textScore, _ := record["textScore"].(float64)
+		vectorScore, _ := record["vectorScore"].(float64)
+		c.textScore, c.vectorScore = float32(textScore), float32(vectorScore)
+		candidates = append(candidates, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if vectorBranch != "" {
+		return fusePassages(candidates, opts.Limit), nil
+	}
+	passages := make([]model.Passage, len(candidates))
+	for i, c := range candidates {
+		passages[i] = c.passage
+		passages[i].Score = c.textScore
+	}
+	sort.SliceStable(passages, func(i, j int) bool {
+		return passages[i].Score > passages[j].Score
+	})
+	return truncate(passages, opts.Limit), nil
+}
+
+// 在只读会话中执行查询，逐条回调结果记录
+func (g *neo4jKnowledgeGraph) read(ctx context.Context, cypherQuery string, params map[string]any, handle func(map[string]any) error) error {
+	session := g.driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, cypherQuery, params)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %v", err)
+	}
+
+	for result.Next(ctx) {
+		if err := handle(result.Record().AsMap()); err != nil {
+			return err
+		}
+	}
+
+	if err = result.Err(); err != nil {
+		return fmt.Errorf("failed to process query results: %v", err)
+	}
+
+	return nil
+}
+
+// 驱动会将 nil 切片作为 null 传入，size(null) 无法用于判断是否过滤
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func escapeKeywords(keywords []string) []string {
+	var escapedKeywords []string
+	for _, k := range keywords {
+		if k == "" {
+			continue
+		}
+		cleanK := sanitizeLuceneQuery(k)
+		if cleanK != "" {
+			escapedKeywords = append(escapedKeywords, cleanK)
+		}
+	}
+	return escapedKeywords
+}
+
+// 转义 Lucene 保留字符
+func sanitizeLuceneQuery(query string) string {
+	replacer := strings.NewReplacer(
+		"+", "\\+", "-", "\\-", "&", "\\&", "|", "\\|",
+		"!", "\\!", "(", "\\(", ")", "\\)", "{", "\\{",
+		"}", "\\}", "[", "\\[", "]", "\\]", "^", "\\^",
+		"\"", "\\\"", "~", "\\~", "*", "\\*", "?", "\\?",
+		":", "\\:", "\\", "\\\\", "/", "\\/",
+	)
+	return replacer.Replace(query)
+}
@@ -0,0 +1,32 @@
+package dao
+
+import (
+	"diabetes-care-mcp-server/model"
+	"strings"
+	"testing"
+)
+
+func TestRelationTypePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		types   []string
+		want    string
+		wantErr bool
+	}{
+		{"selected types", []string{"ADE_Drug", "Drug_Disease"}, "ADE_Drug|Drug_Disease", false},
+		{"all relation types by default", nil, strings.Join(model.DiaKGRelationTypes, "|"), false},
+		{"document structure rejected", []string{"CONTAINS_ENTITY"}, "", true},
+		{"injection rejected", []string{"ADE_Drug*..]-() DETACH DELETE a //"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := relationTypePattern(tt.types)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("relationTypePattern() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("relationTypePattern() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
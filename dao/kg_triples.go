@@ -0,0 +1,178 @@
+package dao
+
+import (
+	"bufio"
+	"bytes"
+	"diabetes-care-mcp-server/model"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 知识图谱数据文件格式
+const (
+	KGFormatDiaKG  = "diakg"
+	KGFormatCSV    = "csv"
+	KGFormatJSONL  = "jsonl"
+	KGFormatTurtle = "turtle"
+)
+
+var KGFormats = []string{KGFormatDiaKG, KGFormatCSV, KGFormatJSONL, KGFormatTurtle}
+
+// SnapshotTriplesFile DiaKG 快照目录中无法还原到文档的关系所在的文件，按 CSV 三元组读取
+const SnapshotTriplesFile = "triples.csv"
+
+// csvTripleColumns CSV 三元组文件的表头，source 列可省略
+var csvTripleColumns = []string{"head", "head_type", "relation", "tail", "tail_type", "source"}
+
+// DetectKGFormat 根据扩展名判断数据文件格式，无法识别时返回空字符串
+func DetectKGFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return KGFormatDiaKG
+	case ".csv":
+		return KGFormatCSV
+	case ".jsonl", ".ndjson":
+		return KGFormatJSONL
+	case ".ttl":
+		return KGFormatTurtle
+	default:
+		return ""
+	}
+}
+
+// GlobKGFiles 返回匹配 pattern 的数据文件；匹配到 DiaKG 文档的目录中若有 triples.csv，
+// 即使 pattern 未匹配到也一并返回，以免导出的快照按默认的 *.json 加载时丢失其中的关系
+func GlobKGFiles(pattern string) ([]string, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]bool, len(files))
+	for _, f := range files {
+		matched[filepath.Clean(f)] = true
+	}
+	for _, f := range files {
+		if DetectKGFormat(f) != KGFormatDiaKG {
+			continue
+		}
+		triples := filepath.Join(filepath.Dir(f), SnapshotTriplesFile)
+		if matched[triples] {
+			continue
+		}
+		if info, err := os.Stat(triples); err == nil && !info.IsDir() {
+			matched[triples] = true
+			files = append(files, triples)
+		}
+	}
+	return files, nil
+}
+
+// TripleSource 三元组文件的默认数据集名称：文件名去掉扩展名
+func TripleSource(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// ReadTriples 按格式读取三元组文件，只检查语法，实体和关系类型由调用方校验
+func ReadTriples(path, format string) ([]model.KGTriple, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %v", path, err)
+	}
+
+	switch format {
+	case KGFormatCSV:
+		return parseCSVTriples(data)
+	case KGFormatJSONL:
+		return parseJSONLTriples(data)
+	case KGFormatTurtle:
+		return parseTurtleTriples(data)
+	default:
+		return nil, fmt.Errorf("unsupported triple format: %s", format)
+	}
+}
+
+func parseCSVTriples(data []byte) ([]model.KGTriple, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %v", err)
+	}
+	columns := csvColumns(header)
+	for _, name := range csvTripleColumns[:5] {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header must contain columns %v", csvTripleColumns)
+		}
+	}
+
+	var triples []model.KGTriple
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %v", err)
+		}
+
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		triples = append(triples, model.KGTriple{
+			Head:     get("head"),
+			HeadType: get("head_type"),
+			Relation: get("relation"),
+			Tail:     get("tail"),
+			TailType: get("tail_type"),
+			Source:   get("source"),
+		})
+	}
+
+	return triples, nil
+}
+
+// csvColumns 表头列名（小写）到列序号的映射
+func csvColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for i, name := range header {
+		// 去掉 Excel 导出时可能带有的 BOM
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	return columns
+}
+
+func parseJSONLTriples(data []byte) ([]model.KGTriple, error) {
+	var triples []model.KGTriple
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var t model.KGTriple
+		if err := json.Unmarshal([]byte(text), &t); err != nil {
+			return nil, fmt.Errorf("error unmarshaling JSON at line %d: %v", line, err)
+		}
+		triples = append(triples, t)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading JSON Lines: %v", err)
+	}
+
+	return triples, nil
+}
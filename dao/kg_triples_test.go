@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGlobKGFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"snapshot/doc1.json":    "{}",
+		"snapshot/doc2.json":    "{}",
+		"snapshot/triples.csv":  "",
+		"triples/triples.csv":   "",
+		"triples/extra.jsonl":   "",
+		"no-triples/doc3.json":  "{}",
+		"snapshot/notes.txt":    "",
+		"triples.csv/doc4.json": "{}",
+	})
+	rel := func(files []string) []string {
+		var out []string
+		for _, f := range files {
+			r, _ := filepath.Rel(dir, f)
+			out = append(out, filepath.ToSlash(r))
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{
+			name:    "snapshot triples added to documents",
+			pattern: "snapshot/*.json",
+			want:    []string{"snapshot/doc1.json", "snapshot/doc2.json", "snapshot/triples.csv"},
+		},
+		{
+			name:    "already matched triples not repeated",
+			pattern: "snapshot/*",
+			want:    []string{"snapshot/doc1.json", "snapshot/doc2.json", "snapshot/notes.txt", "snapshot/triples.csv"},
+		},
+		{
+			name:    "directories without documents unchanged",
+			pattern: "triples/*.jsonl",
+			want:    []string{"triples/extra.jsonl"},
+		},
+		{
+			name:    "directories without triples unchanged",
+			pattern: "no-triples/*.json",
+			want:    []string{"no-triples/doc3.json"},
+		},
+		{
+			name:    "directory named triples.csv ignored",
+			pattern: "triples.csv/*.json",
+			want:    []string{"triples.csv/doc4.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := GlobKGFiles(filepath.Join(dir, tt.pattern))
+			if err != nil {
+				t.Fatalf("GlobKGFiles: %v", err)
+			}
+			if got := rel(files); !slices.Equal(got, tt.want) {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMemoryKnowledgeGraphSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"doc1.json": `{"doc_id": "doc1", "paragraphs": [{"paragraph_id": "p1", "paragraph": "二甲双胍用于2型糖尿病。",
+			"sentences": [{"sentence_id": "s1", "sentence": "二甲双胍用于2型糖尿病。",
+				"entities": [
+					{"entity_id": "T1", "entity": "二甲双胍", "entity_type": "Drug"},
+					{"entity_id": "T2", "entity": "2型糖尿病", "entity_type": "Disease"}
+				],
+				"relations": [{"relation_type": "Drug_Disease", "relation_id": "R1", "head_entity_id": "T1", "tail_entity_id": "T2"}]
+			}]}]}`,
+		"triples.csv": "head,head_type,relation,tail,tail_type,source,weight\n" +
+			"低血糖,ADE,ADE_Drug,二甲双胍,Drug,drug-manual,1\n",
+	})
+
+	g, err := LoadMemoryKnowledgeGraph(context.Background(), filepath.Join(dir, "*.json"), "", nil)
+	if err != nil {
+		t.Fatalf("LoadMemoryKnowledgeGraph: %v", err)
+	}
+	relations, err := g.GetNeighbours(context.Background(), "二甲双胍", 0)
+	if err != nil {
+		t.Fatalf("GetNeighbours: %v", err)
+	}
+	var got []string
+	for _, r := range relations {
+		got = append(got, r.Type+" "+r.Related.Name)
+	}
+	want := []string{"Drug_Disease 2型糖尿病", "ADE_Drug 低血糖"}
+	if !slices.Equal(got, want) {
+		t.Errorf("relations = %v, want %v", got, want)
+	}
+}
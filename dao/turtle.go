@@ -0,0 +1,477 @@
+package dao
+
+import (
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// 支持 Turtle 的常用子集：@prefix/@base 及 SPARQL 风格的 PREFIX/BASE、IRI、前缀名、空白节点标签、a、
+// 字符串/数字/布尔字面量以及 ; 和 , 分隔的谓语、宾语列表。以下特性不支持：
+//   - 空白节点属性列表 [...]（包括匿名空白节点 []）和集合 (...)，遇到时报错
+//   - 前缀名本地名中的反斜杠转义（如 ex:a\-b），遇到时报错
+//   - 相对 IRI 只与 @base 直接拼接，不按 RFC 3986 解析 ../ 等路径
+//   - 字面量的语言标签和数据类型会被解析但不保留，多语言的 rdfs:label 取文件中出现的第一个
+//   - TriG、N-Quads 等含命名图的格式
+//
+// 映射规则：rdf:type 的宾语给出实体类型，rdfs:label 或 skos:prefLabel 给出实体名称（缺省为 IRI 的本地名），
+// dcterms:source 给出来源数据集，宾语为资源的其余陈述作为关系，关系类型取谓语的本地名
+
+const (
+	rdfType       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	rdfsLabel     = "http://www.w3.org/2000/01/rdf-schema#label"
+	skosPrefLabel = "http://www.w3.org/2004/02/skos/core#prefLabel"
+	dctSource     = "http://purl.org/dc/terms/source"
+)
+
+type rdfTerm struct {
+	value   string
+	literal bool
+}
+
+type rdfStatement struct {
+	subject   string
+	predicate string
+	object    rdfTerm
+}
+
+type turtleParser struct {
+	src        []rune
+	pos        int
+	line       int
+	base       string
+	prefixes   map[string]string
+	statements []rdfStatement
+}
+
+func parseTurtleTriples(data []byte) ([]model.KGTriple, error) {
+	statements, err := parseTurtle(data)
+	if err != nil {
+		return nil, err
+	}
+	return turtleToTriples(statements), nil
+}
+
+// parseTurtle 解析出文件中的全部陈述
+func parseTurtle(data []byte) ([]rdfStatement, error) {
+	p := &turtleParser{
+		src:      []rune(string(data)),
+		line:     1,
+		prefixes: make(map[string]string),
+	}
+	for {
+		p.skipSpace()
+		if p.eof() {
+			break
+		}
+		if err := p.statement(); err != nil {
+			return nil, fmt.Errorf("turtle syntax error at line %d: %v", p.line, err)
+		}
+	}
+	return p.statements, nil
+}
+
+func turtleToTriples(statements []rdfStatement) []model.KGTriple {
+	types := make(map[string]string)
+	names := make(map[string]string)
+	sources := make(map[string]string)
+	var relations []rdfStatement
+
+	for _, st := range statements {
+		switch {
+		case st.predicate == rdfType && !st.object.literal:
+			types[st.subject] = localName(st.object.value)
+		case (st.predicate == rdfsLabel || st.predicate == skosPrefLabel) && st.object.literal:
+			if _, ok := names[st.subject]; !ok {
+				names[st.subject] = st.object.value
+			}
+		case st.predicate == dctSource && st.object.literal:
+			sources[st.subject] = st.object.value
+		case !st.object.literal:
+			relations = append(relations, st)
+		}
+	}
+
+	name := func(resource string) string {
+		if n, ok := names[resource]; ok {
+			return n
+		}
+		return localName(resource)
+	}
+
+	triples := make([]model.KGTriple, 0, len(relations))
+	for _, r := range relations {
+		triples = append(triples, model.KGTriple{
+			Head:     name(r.subject),
+			HeadType: types[r.subject],
+			Relation: localName(r.predicate),
+			Tail:     name(r.object.value),
+			TailType: types[r.object.value],
+			Source:   sources[r.subject],
+		})
+	}
+	return triples
+}
+
+// 取 IRI 最后一个 #、/ 或 : 之后的部分，并还原百分号编码
+func localName(iri string) string {
+	name := iri[strings.LastIndexAny(iri, "#/:")+1:]
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+func (p *turtleParser) statement() error {
+	switch {
+	case p.consumeKeyword("@prefix", false):
+		return p.prefixDirective(true)
+	case p.consumeKeyword("PREFIX", true):
+		return p.prefixDirective(false)
+	case p.consumeKeyword("@base", false):
+		return p.baseDirective(true)
+	case p.consumeKeyword("BASE", true):
+		return p.baseDirective(false)
+	}
+
+	subject, err := p.resource()
+	if err != nil {
+		return err
+	}
+	if err := p.predicateObjectList(subject); err != nil {
+		return err
+	}
+	return p.expect('.')
+}
+
+func (p *turtleParser) prefixDirective(dot bool) error {
+	p.skipSpace()
+	prefix := p.readWhile(isNameChar)
+	if err := p.expect(':'); err != nil {
+		return err
+	}
+	p.skipSpace()
+	iri, err := p.iriRef()
+	if err != nil {
+		return err
+	}
+	p.prefixes[prefix] = iri
+	if dot {
+		return p.expect('.')
+	}
+	return nil
+}
+
+func (p *turtleParser) baseDirective(dot bool) error {
+	p.skipSpace()
+	iri, err := p.iriRef()
+	if err != nil {
+		return err
+	}
+	p.base = iri
+	if dot {
+		return p.expect('.')
+	}
+	return nil
+}
+
+func (p *turtleParser) predicateObjectList(subject string) error {
+	for {
+		p.skipSpace()
+		predicate, err := p.verb()
+		if err != nil {
+			return err
+		}
+
+		for {
+			object, err := p.object()
+			if err != nil {
+				return err
+			}
+			p.statements = append(p.statements, rdfStatement{subject: subject, predicate: predicate, object: object})
+
+			p.skipSpace()
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+
+		if p.peek() != ';' {
+			return nil
+		}
+		for p.peek() == ';' {
+			p.pos++
+			p.skipSpace()
+		}
+		// 允许谓语列表以 ; 结尾
+		if p.peek() == '.' {
+			return nil
+		}
+	}
+}
+
+func (p *turtleParser) verb() (string, error) {
+	if p.consumeKeyword("a", false) {
+		return rdfType, nil
+	}
+	return p.resource()
+}
+
+func (p *turtleParser) object() (rdfTerm, error) {
+	p.skipSpace()
+	switch r := p.peek(); {
+	case r == '"' || r == '\'':
+		value, err := p.stringLiteral()
+		return rdfTerm{value: value, literal: true}, err
+	case unicode.IsDigit(r) || r == '+' || r == '-' || r == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1]):
+		return rdfTerm{value: p.number(), literal: true}, nil
+	case p.consumeKeyword("true", false):
+		return rdfTerm{value: "true", literal: true}, nil
+	case p.consumeKeyword("false", false):
+		return rdfTerm{value: "false", literal: true}, nil
+	}
+	value, err := p.resource()
+	return rdfTerm{value: value}, err
+}
+
+// resource 读取 IRI、前缀名或空白节点标签，返回完整 IRI
+func (p *turtleParser) resource() (string, error) {
+	p.skipSpace()
+	switch p.peek() {
+	case '<':
+		return p.iriRef()
+	case '[', '(':
+		return "", fmt.Errorf("blank node property lists and collections are not supported")
+	}
+
+	prefix := p.readWhile(isNameChar)
+	if err := p.expect(':'); err != nil {
+		return "", err
+	}
+	local := p.readWhile(func(r rune) bool { return isNameChar(r) || r == ':' || r == '%' })
+	// 本地名不能以 . 结尾，结尾的 . 是语句结束符
+	for strings.HasSuffix(local, ".") {
+		local = local[:len(local)-1]
+		p.pos--
+	}
+
+	if prefix == "_" {
+		return "_:" + local, nil
+	}
+	ns, ok := p.prefixes[prefix]
+	if !ok {
+		return "", fmt.Errorf("undefined prefix %q", prefix)
+	}
+	return ns + local, nil
+}
+
+func (p *turtleParser) iriRef() (string, error) {
+	if err := p.expect('<'); err != nil {
+		return "", err
+	}
+	start := p.pos
+	for !p.eof() && p.peek() != '>' {
+		if p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated IRI")
+		}
+		p.pos++
+	}
+	if p.eof() {
+		return "", fmt.Errorf("unterminated IRI")
+	}
+	iri := string(p.src[start:p.pos])
+	p.pos++
+
+	// 相对 IRI 基于 @base 解析
+	if p.base != "" && !strings.Contains(iri, ":") {
+		iri = p.base + iri
+	}
+	return iri, nil
+}
+
+func (p *turtleParser) stringLiteral() (string, error) {
+	quote := p.peek()
+	long := p.pos+2 < len(p.src) && p.src[p.pos+1] == quote && p.src[p.pos+2] == quote
+	if long {
+		p.pos += 3
+	} else {
+		p.pos++
+	}
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("unterminated string literal")
+		}
+		r := p.src[p.pos]
+		if long && r == quote && p.pos+2 < len(p.src) && p.src[p.pos+1] == quote && p.src[p.pos+2] == quote {
+			p.pos += 3
+			break
+		}
+		if !long && r == quote {
+			p.pos++
+			break
+		}
+		if !long && r == '\n' {
+			return "", fmt.Errorf("unterminated string literal")
+		}
+		if r == '\n' {
+			p.line++
+		}
+		if r == '\\' {
+			escaped, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(escaped)
+			continue
+		}
+		b.WriteRune(r)
+		p.pos++
+	}
+
+	// 忽略语言标签和数据类型
+	switch {
+	case p.peek() == '@':
+		p.pos++
+		p.readWhile(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' })
+	case p.peek() == '^' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '^':
+		p.pos += 2
+		if _, err := p.resource(); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func (p *turtleParser) escape() (rune, error) {
+	p.pos++
+	if p.eof() {
+		return 0, fmt.Errorf("unterminated escape sequence")
+	}
+	r := p.src[p.pos]
+	p.pos++
+	switch r {
+	case 't':
+		return '\t', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case '"', '\'', '\\':
+		return r, nil
+	case 'u', 'U':
+		n := 4
+		if r == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.src) {
+			return 0, fmt.Errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.src[p.pos:p.pos+n]), 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unicode escape")
+		}
+		p.pos += n
+		return rune(code), nil
+	default:
+		return 0, fmt.Errorf("invalid escape sequence \\%c", r)
+	}
+}
+
+func (p *turtleParser) number() string {
+	start := p.pos
+	p.pos++
+	for !p.eof() {
+		r := p.peek()
+		// . 后面不是数字时为语句结束符
+		if unicode.IsDigit(r) || r == 'e' || r == 'E' || r == '+' || r == '-' ||
+			r == '.' && p.pos+1 < len(p.src) && unicode.IsDigit(p.src[p.pos+1]) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return string(p.src[start:p.pos])
+}
+
+// consumeKeyword 匹配后跟空白、分隔符或结束的关键字
+func (p *turtleParser) consumeKeyword(keyword string, ignoreCase bool) bool {
+	kw := []rune(keyword)
+	end := p.pos + len(kw)
+	if end > len(p.src) {
+		return false
+	}
+	word := string(p.src[p.pos:end])
+	if word != keyword && !(ignoreCase && strings.EqualFold(word, keyword)) {
+		return false
+	}
+	if end < len(p.src) && !unicode.IsSpace(p.src[end]) && !strings.ContainsRune("<\"'.;,", p.src[end]) {
+		return false
+	}
+	p.pos = end
+	return true
+}
+
+func (p *turtleParser) expect(r rune) error {
+	p.skipSpace()
+	if p.peek() != r {
+		if p.eof() {
+			return fmt.Errorf("expected %q, got end of file", r)
+		}
+		return fmt.Errorf("expected %q, got %q", r, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// skipSpace 跳过空白和注释
+func (p *turtleParser) skipSpace() {
+	for !p.eof() {
+		r := p.src[p.pos]
+		switch {
+		case r == '\n':
+			p.line++
+			p.pos++
+		case unicode.IsSpace(r):
+			p.pos++
+		case r == '#':
+			for !p.eof() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *turtleParser) readWhile(f func(rune) bool) string {
+	start := p.pos
+	for !p.eof() && f(p.src[p.pos]) {
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *turtleParser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *turtleParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func isNameChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'
+}
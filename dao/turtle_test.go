@@ -0,0 +1,200 @@
+package dao
+
+import (
+	"diabetes-care-mcp-server/model"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	exNS   = "http://example.org/"
+	rdfsNS = "http://www.w3.org/2000/01/rdf-schema#"
+)
+
+func TestParseTurtle(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want 陈述，格式为 主语 谓语 宾语，字面量宾语加引号
+		want []string
+	}{
+		{
+			name: "prefix and base directives",
+			src: `@prefix ex: <http://example.org/> .
+				PREFIX rdfs: <http://www.w3.org/2000/01/rdf-schema#>
+				@base <http://example.org/> .
+				ex:a rdfs:label "a" .
+				<b> ex:rel <http://other.org/c> .`,
+			want: []string{
+				exNS + "a " + rdfsNS + "label \"a\"",
+				exNS + "b " + exNS + "rel http://other.org/c",
+			},
+		},
+		{
+			name: "predicate and object lists",
+			src: `@prefix ex: <http://example.org/> .
+				ex:a a ex:Drug ;
+					ex:rel ex:b , ex:c ;
+					ex:other ex:d ; .`,
+			want: []string{
+				exNS + "a " + rdfType + " " + exNS + "Drug",
+				exNS + "a " + exNS + "rel " + exNS + "b",
+				exNS + "a " + exNS + "rel " + exNS + "c",
+				exNS + "a " + exNS + "other " + exNS + "d",
+			},
+		},
+		{
+			name: "literals with language tags and datatypes",
+			src: `@prefix ex: <http://example.org/> .
+				@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+				ex:a ex:p "二甲双胍"@zh-Hans , "x"^^xsd:string , "y"^^<http://www.w3.org/2001/XMLSchema#string> , 'z' .`,
+			want: []string{
+				exNS + "a " + exNS + "p \"二甲双胍\"",
+				exNS + "a " + exNS + "p \"x\"",
+				exNS + "a " + exNS + "p \"y\"",
+				exNS + "a " + exNS + "p \"z\"",
+			},
+		},
+		{
+			name: "numbers and booleans",
+			src: `@prefix ex: <http://example.org/> .
+				ex:a ex:p 12 , -1.5 , 2e3 , true , false .
+				ex:b ex:p 3.`,
+			want: []string{
+				exNS + "a " + exNS + "p \"12\"",
+				exNS + "a " + exNS + "p \"-1.5\"",
+				exNS + "a " + exNS + "p \"2e3\"",
+				exNS + "a " + exNS + "p \"true\"",
+				exNS + "a " + exNS + "p \"false\"",
+				exNS + "b " + exNS + "p \"3\"",
+			},
+		},
+		{
+			name: "escapes",
+			src: `@prefix ex: <http://example.org/> .
+				ex:a ex:p "tab\there\nnew \"quoted\" \\ 二\U00004E8C" .`,
+			want: []string{
+				exNS + "a " + exNS + "p \"tab\there\nnew \"quoted\" \\ 二二\"",
+			},
+		},
+		{
+			name: "long strings",
+			src: "@prefix ex: <http://example.org/> .\n" +
+				"ex:a ex:p \"\"\"line one\nline \"two\" end\"\"\" .\n" +
+				"ex:b ex:p '''single ' quote''' .",
+			want: []string{
+				exNS + "a " + exNS + "p \"line one\nline \"two\" end\"",
+				exNS + "b " + exNS + "p \"single ' quote\"",
+			},
+		},
+		{
+			name: "comments",
+			src: `# leading comment
+				@prefix ex: <http://example.org/> . # after directive
+				ex:a # between terms
+					ex:p "# not a comment" ; # after object
+					ex:q <http://example.org/x#y> .
+				# trailing comment`,
+			want: []string{
+				exNS + "a " + exNS + "p \"# not a comment\"",
+				exNS + "a " + exNS + "q http://example.org/x#y",
+			},
+		},
+		{
+			name: "blank node labels and dotted local names",
+			src: `@prefix ex: <http://example.org/> .
+				_:n1 ex:p ex:v1.2.
+				ex:a.b ex:p _:n1 .`,
+			want: []string{
+				"_:n1 " + exNS + "p " + exNS + "v1.2",
+				exNS + "a.b " + exNS + "p _:n1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, err := parseTurtle([]byte(tt.src))
+			if err != nil {
+				t.Fatalf("parseTurtle: %v", err)
+			}
+			var got []string
+			for _, st := range statements {
+				object := st.object.value
+				if st.object.literal {
+					object = `"` + object + `"`
+				}
+				got = append(got, st.subject+" "+st.predicate+" "+object)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("statements = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTurtleErrors(t *testing.T) {
+	const prefix = "@prefix ex: <http://example.org/> .\n"
+
+	tests := []struct {
+		name string
+		src  string
+		// want 错误信息中应包含的内容
+		want string
+	}{
+		{"undefined prefix", "foo:a foo:b foo:c .", `undefined prefix "foo"`},
+		{"missing dot", prefix + "ex:a ex:p ex:b", "got end of file"},
+		{"missing object", prefix + "ex:a ex:p .", `expected ':'`},
+		{"unterminated string", prefix + `ex:a ex:p "abc .`, "unterminated string literal"},
+		{"newline in short string", prefix + "ex:a ex:p \"ab\nc\" .", "unterminated string literal"},
+		{"unterminated long string", prefix + `ex:a ex:p """abc .`, "unterminated string literal"},
+		{"unterminated IRI", prefix + "ex:a ex:p <http://example.org/b .", "unterminated IRI"},
+		{"invalid escape", prefix + `ex:a ex:p "\q" .`, `invalid escape sequence \q`},
+		{"short unicode escape", prefix + `ex:a ex:p "\u12" .`, "invalid unicode escape"},
+		{"blank node property list", prefix + `ex:a ex:p [ ex:q ex:r ] .`, "not supported"},
+		{"collection", prefix + `ex:a ex:p ( ex:b ex:c ) .`, "not supported"},
+		{"prefix without IRI", "@prefix ex: http://example.org/ .", `expected '<'`},
+		{"line number", prefix + "ex:a ex:p ex:b .\n\nex:c ex:p \"x .", "line 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTurtleTriples([]byte(tt.src))
+			if err == nil {
+				t.Fatalf("parseTurtleTriples succeeded, want error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTurtleToTriples(t *testing.T) {
+	src := `@prefix ex: <http://example.org/> .
+		@prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .
+		@prefix skos: <http://www.w3.org/2004/02/skos/core#> .
+		@prefix dcterms: <http://purl.org/dc/terms/> .
+
+		ex:metformin a ex:Drug ;
+			rdfs:label "二甲双胍"@zh , "metformin"@en ;
+			dcterms:source "drug-manual" ;
+			ex:Drug_Disease ex:t2dm .
+
+		ex:t2dm a ex:Disease ;
+			skos:prefLabel "2型糖尿病" .
+
+		ex:%E4%BD%8E%E8%A1%80%E7%B3%96 a ex:ADE ;
+			ex:ADE_Drug ex:metformin .`
+
+	triples, err := parseTurtleTriples([]byte(src))
+	if err != nil {
+		t.Fatalf("parseTurtleTriples: %v", err)
+	}
+	want := []model.KGTriple{
+		{Head: "二甲双胍", HeadType: "Drug", Relation: "Drug_Disease", Tail: "2型糖尿病", TailType: "Disease", Source: "drug-manual"},
+		{Head: "低血糖", HeadType: "ADE", Relation: "ADE_Drug", Tail: "二甲双胍", TailType: "Drug"},
+	}
+	if !slices.Equal(triples, want) {
+		t.Errorf("triples = %+v, want %+v", triples, want)
+	}
+}
@@ -0,0 +1,60 @@
+package embedding
+
+import (
+	"context"
+	"diabetes-care-mcp-server/config"
+	"fmt"
+	"math"
+)
+
+// Embedder 将文本转换为向量，同一 Embedder 生成的向量可用余弦相似度比较
+type Embedder interface {
+	// Model 向量模型标识，随向量一起保存，避免混用不同模型生成的向量
+	Model() string
+	// Embed 按输入顺序返回每段文本的单位向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// New 根据配置创建 Embedder：配置了 model.embedding_model 时调用远程向量接口，否则使用本地 n-gram 哈希向量
+func New(cfg *config.Config) (Embedder, error) {
+	if cfg.Model.EmbeddingModel == "" {
+		return NewHashingEmbedder(DefaultHashingDimensions), nil
+	}
+	if cfg.Model.APIKey == "" {
+		return nil, fmt.Errorf("model.api_key is required for embedding model %s", cfg.Model.EmbeddingModel)
+	}
+	return NewRemoteEmbedder(cfg.Model.BaseURL, cfg.Model.APIKey, cfg.Model.EmbeddingModel), nil
+}
+
+// Cosine 两个向量的余弦相似度，维度不同或存在零向量时返回 0
+func Cosine(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return float32(dot / math.Sqrt(na*nb))
+}
+
+// normalize 将向量缩放为单位长度，零向量保持不变
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
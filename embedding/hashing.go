@@ -0,0 +1,69 @@
+package embedding
+
+import (
+	"context"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"hash/fnv"
+	"unicode"
+)
+
+// DefaultHashingDimensions 本地哈希向量的默认维度
+const DefaultHashingDimensions = 512
+
+// hashingNGrams 参与哈希的字符 n-gram 长度；中文词语多为两三个字，单字和双字组合足以覆盖“血糖太低”与“低血糖”这类改写
+var hashingNGrams = []int{1, 2}
+
+// HashingEmbedder 将字符 n-gram 哈希到固定维度的本地向量，无需网络，结果确定
+type HashingEmbedder struct {
+	dimensions int
+}
+
+func NewHashingEmbedder(dimensions int) *HashingEmbedder {
+	return &HashingEmbedder{dimensions: dimensions}
+}
+
+func (e *HashingEmbedder) Model() string {
+	return fmt.Sprintf("hashing-ngram-%d", e.dimensions)
+}
+
+func (e *HashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+// embed 对归一化文本中不含空白和标点的片段提取 n-gram，按哈希值的符号位累加，降低冲突带来的偏差
+func (e *HashingEmbedder) embed(text string) []float32 {
+	v := make([]float32, e.dimensions)
+
+	var segment []rune
+	flush := func() {
+		for _, n := range hashingNGrams {
+			for i := 0; i+n <= len(segment); i++ {
+				h := fnv.New64a()
+				h.Write([]byte(string(segment[i : i+n])))
+				sum := h.Sum64()
+				if sum>>63 == 0 {
+					v[sum%uint64(e.dimensions)]++
+				} else {
+					v[sum%uint64(e.dimensions)]--
+				}
+			}
+		}
+		segment = segment[:0]
+	}
+
+	for _, r := range model.NormalizeEntityName(text) {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			flush()
+			continue
+		}
+		segment = append(segment, r)
+	}
+	flush()
+
+	return normalize(v)
+}
@@ -4,26 +4,103 @@ import (
 	"context"
 	"diabetes-care-mcp-server/config"
 	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/middleware"
 	"diabetes-care-mcp-server/server"
+	"flag"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 )
 
+// stdio 模式下用于确定用户身份的 JWT 环境变量
+const tokenEnv = "DIABETES_CARE_MCP_TOKEN"
+
 func main() {
-	setSysLog()
+	configPath := flag.String("config", "config.yaml", "path to config file")
+	transport := flag.String("transport", "", "transport mode: stdio, http or sse (overrides config)")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Failed to load config", "err", err)
+		os.Exit(1)
+	}
+
+	if *transport == "" {
+		*transport = cfg.Server.Transport
+	}
+	if *transport == "" {
+		*transport = "http"
+	}
+
+	// stdio 模式下标准输出用于协议通信，日志写入标准错误
+	var logWriter io.Writer = os.Stdout
+	if *transport == "stdio" {
+		logWriter = os.Stderr
+	}
+	setSysLog(cfg.Server.LogLevel, logWriter)
 
 	ctx := context.Background()
-	defer dao.Driver.Close(ctx)
 
-	s := server.NewHTTPServer()
-	if err := s.Start(":" + config.Cfg.Server.Port); err != nil {
-		slog.Error("Failed to start MCP server", "err", err)
+	d, err := dao.Open(ctx, cfg)
+	if err != nil {
+		slog.Error("Failed to open storage", "err", err)
+		os.Exit(1)
 	}
+	defer d.Close(ctx)
+
+	deps := server.Deps{
+		Config: cfg,
+		DAO:    d,
+	}
+
+	addr := ":" + cfg.Server.Port
+
+	switch *transport {
+	case "http":
+		err = server.NewHTTPServer(deps).Start(addr)
+	case "sse":
+		err = server.NewSSEServer(deps).Start(addr)
+	case "stdio":
+		var email string
+		email, err = stdioUserEmail(cfg)
+		if err != nil {
+			break
+		}
+		deps.Auth = middleware.NewStaticAuthMiddleware(email)
+		err = server.ServeStdio(deps)
+	default:
+		err = fmt.Errorf("unknown transport: %s", *transport)
+	}
+	if err != nil {
+		slog.Error("Failed to start MCP server",
+			"transport", *transport,
+			"err", err,
+		)
+	}
+}
+
+// stdio 模式的用户身份：优先使用环境变量中的 JWT，其次使用配置中的邮箱
+func stdioUserEmail(cfg *config.Config) (string, error) {
+	if token := os.Getenv(tokenEnv); token != "" {
+		claims, err := middleware.ParseToken(token, cfg.JWT.SecretKey)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %w", tokenEnv, err)
+		}
+		return claims.UserEmail, nil
+	}
+
+	if cfg.Server.StdioUserEmail != "" {
+		return cfg.Server.StdioUserEmail, nil
+	}
+
+	return "", fmt.Errorf("stdio transport requires %s or server.stdio_user_email", tokenEnv)
 }
 
-func setSysLog() {
+func setSysLog(logLevel string, w io.Writer) {
 	var level slog.Leveler
-	switch config.Cfg.Server.LogLevel {
+	switch logLevel {
 	case "debug":
 		level = slog.LevelDebug
 	case "info":
@@ -35,7 +112,7 @@ func setSysLog() {
 	default:
 		level = slog.LevelInfo
 	}
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+	slog.SetDefault(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
 		Level: level,
 	})))
 }
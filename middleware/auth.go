@@ -2,7 +2,6 @@ package middleware
 
 import (
 	"context"
-	"diabetes-care-mcp-server/config"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -17,36 +16,50 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func AuthMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
-	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		authHeader := req.Header.Get("Authorization")
-		if authHeader == "" {
-			return nil, fmt.Errorf("missing authorization header")
-		}
+// NewAuthMiddleware 创建校验 JWT 的工具中间件
+func NewAuthMiddleware(secretKey string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			authHeader := req.Header.Get("Authorization")
+			if authHeader == "" {
+				return nil, fmt.Errorf("missing authorization header")
+			}
 
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			return nil, fmt.Errorf("invalid authorization header format")
-		}
+			if !strings.HasPrefix(authHeader, "Bearer ") {
+				return nil, fmt.Errorf("invalid authorization header format")
+			}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
+			token := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims, err := validateToken(token)
-		if err != nil {
-			return nil, fmt.Errorf("invalid token: %w", err)
-		}
+			claims, err := ParseToken(token, secretKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid token: %w", err)
+			}
+
+			// 将用户邮箱添加到上下文
+			ctx = context.WithValue(ctx, "user_email", claims.UserEmail)
 
-		// 将用户邮箱添加到上下文
-		ctx = context.WithValue(ctx, "user_email", claims.UserEmail)
+			return next(ctx, req)
+		}
+	}
+}
 
-		return next(ctx, req)
+// NewStaticAuthMiddleware 以固定用户身份调用工具，用于没有请求头的 stdio 传输
+func NewStaticAuthMiddleware(userEmail string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = context.WithValue(ctx, "user_email", userEmail)
+			return next(ctx, req)
+		}
 	}
 }
 
-func validateToken(tokenString string) (*Claims, error) {
+// ParseToken 校验 JWT 并返回其中的声明
+func ParseToken(tokenString, secretKey string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.Cfg.JWT.SecretKey), nil
+		return []byte(secretKey), nil
 	})
 
 	if err != nil || !token.Valid {
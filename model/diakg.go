@@ -0,0 +1,41 @@
+package model
+
+// DiaKG 数据集的文档结构：文档 -> 段落 -> 句子 -> 实体/关系
+
+// DiaKGSource DiaKG 数据集在图谱中的来源名称
+const DiaKGSource = "diakg"
+
+type DiaKGEntity struct {
+	EntityID   string `json:"entity_id"`
+	Entity     string `json:"entity"`
+	EntityType string `json:"entity_type"`
+	StartIdx   int    `json:"start_idx"`
+	EndIdx     int    `json:"end_idx"`
+}
+
+type DiaKGRelation struct {
+	RelationType string `json:"relation_type"`
+	RelationID   string `json:"relation_id"`
+	HeadEntityID string `json:"head_entity_id"`
+	TailEntityID string `json:"tail_entity_id"`
+}
+
+type DiaKGSentence struct {
+	SentenceID string          `json:"sentence_id"`
+	Sentence   string          `json:"sentence"`
+	StartIdx   int             `json:"start_idx"`
+	EndIdx     int             `json:"end_idx"`
+	Entities   []DiaKGEntity   `json:"entities"`
+	Relations  []DiaKGRelation `json:"relations"`
+}
+
+type DiaKGParagraph struct {
+	ParagraphID string          `json:"paragraph_id"`
+	Paragraph   string          `json:"paragraph"`
+	Sentences   []DiaKGSentence `json:"sentences"`
+}
+
+type DiaKGDocument struct {
+	DocID      string           `json:"doc_id"`
+	Paragraphs []DiaKGParagraph `json:"paragraphs"`
+}
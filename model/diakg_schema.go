@@ -0,0 +1,54 @@
+package model
+
+import "slices"
+
+// DiaKG 标注体系中的实体类型
+var DiaKGEntityTypes = []string{
+	"Disease",      // 疾病
+	"Class",        // 疾病分期分型
+	"Reason",       // 病因
+	"Pathogenesis", // 发病机制
+	"Symptom",      // 临床表现
+	"Test",         // 检查方法
+	"Test_Items",   // 检查指标
+	"Test_Value",   // 检查指标值
+	"Drug",         // 药物名称
+	"Frequency",    // 用药频率
+	"Amount",       // 用药剂量
+	"Method",       // 用药方法
+	"Treatment",    // 非药治疗
+	"Operation",    // 手术
+	"ADE",          // 不良反应
+	"Anatomy",      // 部位
+	"Level",        // 程度
+	"Duration",     // 持续时间
+}
+
+// DiaKG 标注体系中的关系类型，命名为 头实体类型_尾实体类型
+var DiaKGRelationTypes = []string{
+	"Test_Disease",
+	"Symptom_Disease",
+	"Treatment_Disease",
+	"Drug_Disease",
+	"Anatomy_Disease",
+	"Reason_Disease",
+	"Pathogenesis_Disease",
+	"Operation_Disease",
+	"Class_Disease",
+	"Test_Items_Disease",
+	"Frequency_Drug",
+	"Duration_Drug",
+	"Amount_Drug",
+	"Method_Drug",
+	"ADE_Drug",
+}
+
+// IsDiaKGEntityType 判断 t 是否为标注体系中的实体类型
+func IsDiaKGEntityType(t string) bool {
+	return slices.Contains(DiaKGEntityTypes, t)
+}
+
+// IsDiaKGRelationType 判断 t 是否为标注体系中的关系类型
+func IsDiaKGRelationType(t string) bool {
+	return slices.Contains(DiaKGRelationTypes, t)
+}
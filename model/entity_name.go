@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeEntityName 归一化实体名称：全角转半角、合并空白、统一小写
+func NormalizeEntityName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.TrimSpace(name) {
+		r = FoldEntityRune(r)
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FoldEntityRune 按 NormalizeEntityName 的规则转换单个字符：全角转半角、统一小写
+func FoldEntityRune(r rune) rune {
+	switch {
+	case r == '　':
+		r = ' '
+	case r >= '！' && r <= '～':
+		r -= 0xfee0
+	}
+	return unicode.ToLower(r)
+}
+
+// CanonicalEntityKey 规范实体的唯一键，同类型且归一化名称相同的实体视为同一实体
+func CanonicalEntityKey(name, entityType string) string {
+	return entityType + ":" + NormalizeEntityName(name)
+}
+
+// DiaKGRelationID DiaKG 中一次关系标注的唯一标识，标注的关系 ID 只在文档内唯一
+func DiaKGRelationID(docID, relationID string) string {
+	return docID + "/" + relationID
+}
+
+// DiaKGPassageKey DiaKG 中段落或句子的唯一键，段落和句子 ID 只在文档内唯一
+func DiaKGPassageKey(docID, id string) string {
+	return docID + "/" + id
+}
+
+// TripleRelationID 外部数据集中一条三元组的唯一标识，同一数据集重复给出的三元组只计一次
+func TripleRelationID(source, headKey, relation, tailKey string) string {
+	return source + "/" + headKey + "-" + relation + "->" + tailKey
+}
@@ -0,0 +1,51 @@
+package model
+
+import "math"
+
+const (
+	GlucoseUnitMmolL = "mmol/L"
+	GlucoseUnitMgdL  = "mg/dL"
+
+	// MgdLPerMmolL mmol/L 与 mg/dL 的换算系数
+	MgdLPerMmolL = 18.018
+
+	// mmol/L 读数不会超过该值，更大的数值视为 mg/dL
+	maxMmolLReading = 35
+)
+
+var GlucoseUnits = []string{GlucoseUnitMmolL, GlucoseUnitMgdL}
+
+// ConvertGlucose 在两种单位之间换算血糖值
+func ConvertGlucose(value float64, from, to string) float64 {
+	if from == to {
+		return value
+	}
+	if to == GlucoseUnitMgdL {
+		return value * MgdLPerMmolL
+	}
+	return value / MgdLPerMmolL
+}
+
+// RoundGlucose 按单位的常用精度取整：mmol/L 保留两位小数，mg/dL 保留一位小数
+func RoundGlucose(value float64, unit string) float64 {
+	if unit == GlucoseUnitMgdL {
+		return math.Round(value*10) / 10
+	}
+	return math.Round(value*100) / 100
+}
+
+// InferGlucoseUnit 根据数值大小推断血糖单位，仅用于未标明单位的历史记录
+func InferGlucoseUnit(value float64) string {
+	if value > maxMmolLReading {
+		return GlucoseUnitMgdL
+	}
+	return GlucoseUnitMmolL
+}
+
+// StoredGlucoseUnit 返回存储的血糖值的单位，unit 为空时按数值推断，inferred 表示单位为推断所得
+func StoredGlucoseUnit(value float32, unit string) (string, bool) {
+	if unit != "" {
+		return unit, false
+	}
+	return InferGlucoseUnit(float64(value)), true
+}
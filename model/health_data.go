@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// 新记录的血糖值统一以 mmol/L 存储并在 GlucoseUnit 中标明单位，未标明单位的历史记录按数值推断单位，
+// 返回给调用方前按请求的单位换算
+
+type BloodGlucoseRecord struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Value        float32   `json:"value"`
+	MeasuredAt   time.Time `json:"measuredAt"`
+	DiningStatus string    `json:"diningStatus"`
+	// GlucoseUnit Value 的单位，为空表示历史记录未标明单位
+	GlucoseUnit string `json:"-" gorm:"size:8"`
+	// UnitInferred 存储时未标明单位，换算按数值推断的单位进行，结果可能有误
+	UnitInferred bool `json:"unit_inferred,omitempty" gorm:"-"`
+}
+
+type HealthProfile struct {
+	Gender            string  `json:"gender"`
+	Age               int     `json:"age"`
+	Height            float32 `json:"height"`
+	Weight            float32 `json:"weight"`
+	DietaryPreference string  `json:"dietary_preference"`
+	SmokingStatus     bool    `json:"smoking_status"`
+	ActivityLevel     string  `json:"activity_level"`
+	DiabetesType      string  `json:"diabetes_type"`
+	DiagnosisYear     int     `json:"diagnosis_year"`
+	TherapyMode       string  `json:"therapy_mode"`
+	Medication        string  `json:"medication"`
+	Allergies         string  `json:"allergies"`
+	Complications     string  `json:"complications"`
+	// GlucoseUnit 用户偏好的血糖单位，为空时使用 mmol/L
+	GlucoseUnit string `json:"glucose_unit" gorm:"size:8"`
+}
+
+type ExerciseRecord struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Type        string    `json:"type"`
+	Name        string    `json:"name"`
+	Intensity   string    `json:"intensity"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+	Duration    int       `json:"duration"`
+	PreGlucose  float32   `json:"pre_glucose"`
+	PostGlucose float32   `json:"post_glucose"`
+	Notes       string    `json:"notes"`
+	// GlucoseUnit PreGlucose 和 PostGlucose 的单位，为空表示历史记录未标明单位
+	GlucoseUnit string `json:"-" gorm:"size:8"`
+	// UnitInferred 存储时未标明单位，换算按数值推断的单位进行，结果可能有误
+	UnitInferred bool `json:"unit_inferred,omitempty" gorm:"-"`
+}
@@ -0,0 +1,131 @@
+package model
+
+import "fmt"
+
+type KnowlegeGraphSearchResult struct {
+	Node          EntityNode `json:"node"`
+	Relationships []Relation `json:"relationships"`
+	Score         float32    `json:"score"`
+	// MatchedAlias 实体通过别名而非名称被检索到时匹配的别名
+	MatchedAlias string `json:"matched_alias,omitempty"`
+}
+
+// EntityNode 图谱中的实体，Sources 为包含该实体的数据集，Aliases 为别名词典中该实体的别名
+type EntityNode struct {
+	ID      string   `json:"id,omitempty"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Sources []string `json:"sources,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// EntityAlias 别名词典中的一条别名，Type 为空时适用于所有同名实体
+type EntityAlias struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Alias string `json:"alias"`
+}
+
+// Relation 实体的一条直接关系，Weight 为数据集中该关系被标注的次数，Sources 为给出该关系的数据集
+type Relation struct {
+	Type    string     `json:"type"`
+	Related EntityNode `json:"related"`
+	Weight  int        `json:"weight,omitempty"`
+	Sources []string   `json:"sources,omitempty"`
+}
+
+// Triple 带方向的关系，Hop 为距查询实体的跳数
+type Triple struct {
+	Head    EntityNode `json:"head"`
+	Type    string     `json:"type"`
+	Tail    EntityNode `json:"tail"`
+	Hop     int        `json:"hop"`
+	Weight  int        `json:"weight,omitempty"`
+	Sources []string   `json:"sources,omitempty"`
+}
+
+// KGTriple 外部数据集中的一条三元组，Source 为空时由导入方指定数据集名称
+type KGTriple struct {
+	Head     string `json:"head"`
+	HeadType string `json:"head_type"`
+	Relation string `json:"relation"`
+	Tail     string `json:"tail"`
+	TailType string `json:"tail_type"`
+	Source   string `json:"source"`
+}
+
+// Validate 检查三元组字段完整且实体、关系类型属于 DiaKG 标注体系
+func (t KGTriple) Validate() error {
+	if t.Head == "" || t.HeadType == "" || t.Relation == "" || t.Tail == "" || t.TailType == "" {
+		return fmt.Errorf("incomplete triple")
+	}
+	for _, typ := range []string{t.HeadType, t.TailType} {
+		if !IsDiaKGEntityType(typ) {
+			return fmt.Errorf("unknown entity type %q", typ)
+		}
+	}
+	if !IsDiaKGRelationType(t.Relation) {
+		return fmt.Errorf("unknown relation type %q", t.Relation)
+	}
+	return nil
+}
+
+// QueryTerm 查询文本中识别出的实体名称，Start/End 为其在查询中的字符偏移，Entities 为同名的实体
+type QueryTerm struct {
+	Text     string       `json:"text"`
+	Start    int          `json:"start"`
+	End      int          `json:"end"`
+	Entities []EntityNode `json:"entities"`
+}
+
+// EvidenceSentence 实体被抽取时所在的原文句子
+type EvidenceSentence struct {
+	DocID       string `json:"doc_id"`
+	ParagraphID string `json:"paragraph_id"`
+	SentenceID  string `json:"sentence_id"`
+	Text        string `json:"text"`
+}
+
+type EvidenceParagraph struct {
+	DocID       string `json:"doc_id"`
+	ParagraphID string `json:"paragraph_id"`
+	Text        string `json:"text"`
+}
+
+// EntityDetails 实体的多跳关系及其出处
+type EntityDetails struct {
+	Entity     EntityNode          `json:"entity"`
+	Relations  []Triple            `json:"relations"`
+	Sentences  []EvidenceSentence  `json:"sentences"`
+	Paragraphs []EvidenceParagraph `json:"paragraphs"`
+}
+
+// Path 两个实体间的一条路径，Relations[i] 连接 Nodes[i] 与 Nodes[i+1]
+type Path struct {
+	Nodes     []EntityNode   `json:"nodes"`
+	Relations []PathRelation `json:"relations"`
+	Length    int            `json:"length"`
+}
+
+// PathRelation 路径中的一条关系，Forward 表示关系方向与路径方向一致
+type PathRelation struct {
+	Type    string `json:"type"`
+	Forward bool   `json:"forward"`
+}
+
+// 原文检索结果的粒度
+const (
+	PassageLevelSentence  = "sentence"
+	PassageLevelParagraph = "paragraph"
+)
+
+// Passage 原文检索命中的句子或段落，Level 为 sentence 时 SentenceID 不为空，Entities 为其中标注的实体
+type Passage struct {
+	Level       string       `json:"level"`
+	DocID       string       `json:"doc_id"`
+	ParagraphID string       `json:"paragraph_id"`
+	SentenceID  string       `json:"sentence_id,omitempty"`
+	Text        string       `json:"text"`
+	Score       float32      `json:"score"`
+	Entities    []EntityNode `json:"entities"`
+}
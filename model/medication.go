@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// MedicationRegimen 用药方案中的一种药物，用药区间为 [StartDate, StopDate)，StopDate 为空表示仍在使用
+type MedicationRegimen struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Drug      string     `json:"drug"`
+	Dose      float32    `json:"dose"`
+	Unit      string     `json:"unit"`
+	Frequency string     `json:"frequency"`
+	Route     string     `json:"route"`
+	StartDate time.Time  `json:"start_date"`
+	StopDate  *time.Time `json:"stop_date,omitempty"`
+	// EntityID 对应的图谱 Drug 实体 ID，为空表示未关联
+	EntityID string `json:"entity_id,omitempty"`
+	Notes    string `json:"notes"`
+}
+
+// ActiveAt 判断 t 时刻方案是否在使用
+func (r MedicationRegimen) ActiveAt(t time.Time) bool {
+	return !t.Before(r.StartDate) && (r.StopDate == nil || t.Before(*r.StopDate))
+}
+
+// MedicationFrequencies 用药频率，as_needed 为按需用药，没有固定的服药次数
+var MedicationFrequencies = []string{
+	"once_daily", "twice_daily", "three_times_daily", "four_times_daily",
+	"every_other_day", "weekly", "as_needed",
+}
+
+var dosesPerDay = map[string]float64{
+	"once_daily":        1,
+	"twice_daily":       2,
+	"three_times_daily": 3,
+	"four_times_daily":  4,
+	"every_other_day":   1.0 / 2,
+	"weekly":            1.0 / 7,
+}
+
+// DosesPerDay 用药频率对应的每日服药次数，按需用药返回 0
+func DosesPerDay(frequency string) float64 {
+	return dosesPerDay[frequency]
+}
+
+// 服药记录的状态
+const (
+	DoseStatusTaken   = "taken"
+	DoseStatusSkipped = "skipped"
+	DoseStatusLate    = "late"
+)
+
+var DoseStatuses = []string{DoseStatusTaken, DoseStatusSkipped, DoseStatusLate}
+
+// MedicationDose 一次服药记录，跳过时 TakenAt 为原定的服药时间
+type MedicationDose struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	RegimenID uint      `json:"regimen_id" gorm:"index"`
+	Status    string    `json:"status"`
+	TakenAt   time.Time `json:"taken_at"`
+	Notes     string    `json:"notes"`
+}
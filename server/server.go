@@ -1,7 +1,10 @@
 package server
 
 import (
+	"diabetes-care-mcp-server/config"
+	"diabetes-care-mcp-server/dao"
 	"diabetes-care-mcp-server/middleware"
+	"diabetes-care-mcp-server/model"
 	"diabetes-care-mcp-server/tools"
 	_ "embed"
 
@@ -17,30 +20,58 @@ const (
 //go:embed prompts/search_diabetes_kg/query.txt
 var searchDiabetesKGQueryDesc string
 
-func NewHTTPServer() *server.StreamableHTTPServer {
+// Deps 服务运行所需的依赖
+type Deps struct {
+	Config *config.Config
+	DAO    *dao.DAO
+	// Auth 工具调用的认证中间件，为空时校验请求头中的 JWT
+	Auth server.ToolHandlerMiddleware
+}
+
+// New 创建 MCP 服务并注册 hook 与工具
+func New(deps Deps) *server.MCPServer {
 	hooks := &server.Hooks{}
 
 	// 注册 hook，推送工具调用结果
 	hooks.AddAfterCallTool(pushCallToolResult)
 
+	auth := deps.Auth
+	if auth == nil {
+		auth = middleware.NewAuthMiddleware(deps.Config.JWT.SecretKey)
+	}
+
 	s := server.NewMCPServer(serverName, serverVersion,
 		server.WithToolCapabilities(true),
-		server.WithToolHandlerMiddleware(middleware.AuthMiddleware),
+		server.WithToolHandlerMiddleware(auth),
 		server.WithHooks(hooks),
 	)
 
-	registerTools(s)
+	registerTools(s, tools.NewTools(deps.DAO.HealthData, deps.DAO.KnowledgeGraph))
+
+	return s
+}
+
+func NewHTTPServer(deps Deps) *server.StreamableHTTPServer {
+	return server.NewStreamableHTTPServer(New(deps))
+}
+
+func NewSSEServer(deps Deps) *server.SSEServer {
+	return server.NewSSEServer(New(deps))
+}
 
-	return server.NewStreamableHTTPServer(s)
+// ServeStdio 通过标准输入输出提供服务，阻塞直到输入结束
+func ServeStdio(deps Deps) error {
+	return server.ServeStdio(New(deps))
 }
 
-func registerTools(s *server.MCPServer) {
+func registerTools(s *server.MCPServer, t *tools.Tools) {
 	s.AddTool(
 		mcp.NewTool("search_diabetes_knowledge_graph",
 			mcp.WithDescription(`
 				Search professional information about diabetes guidelines, medications, diagnostics, and treatments. 
-				Returns structured data from knowledge graph (entities and relationships). 
-				All results are sorted by relevance score in descending order.
+				Returns structured data from knowledge graph (entities and relationships), along with the entity 
+				names detected in the query. Entities are matched by name and by semantic similarity, and all results
+				are sorted by the combined relevance score in descending order.
 			`),
 			mcp.WithString("query",
 				mcp.Required(),
@@ -51,24 +82,446 @@ func registerTools(s *server.MCPServer) {
 				mcp.Max(30),
 				mcp.Description("Number of results to return (10-30)"),
 			),
+			mcp.WithArray("entity_types",
+				mcp.WithStringEnumItems(model.DiaKGEntityTypes),
+				mcp.Description("Only return entities of these DiaKG types, e.g. [\"Drug\"] for medication questions"),
+			),
+			mcp.WithArray("relation_types",
+				mcp.WithStringEnumItems(model.DiaKGRelationTypes),
+				mcp.Description("Only return relationships of these DiaKG types; entities without such relationships are omitted"),
+			),
 		),
-		tools.SearchDiabetesKnowledgeGraph,
+		t.SearchDiabetesKnowledgeGraph,
+	)
+
+	s.AddTool(
+		mcp.NewTool("get_entity_details",
+			mcp.WithDescription(`
+				Get details of a knowledge graph entity: its typed relations up to N hops away, and the original 
+				guideline sentences and paragraphs the entity was extracted from, so answers can quote evidence.
+			`),
+			mcp.WithString("entity",
+				mcp.Required(),
+				mcp.Description("Entity id (from search results) or exact entity name"),
+			),
+			mcp.WithNumber("hops",
+				mcp.Min(1),
+				mcp.Max(3),
+				mcp.Description("Maximum relation hops to expand (1-3, default 1)"),
+			),
+			mcp.WithNumber("relation_limit",
+				mcp.Min(1),
+				mcp.Max(200),
+				mcp.Description("Maximum number of relations to return (default 50)"),
+			),
+			mcp.WithNumber("evidence_limit",
+				mcp.Min(1),
+				mcp.Max(50),
+				mcp.Description("Maximum number of source sentences to return (default 10)"),
+			),
+		),
+		t.GetEntityDetails,
+	)
+
+	s.AddTool(
+		mcp.NewTool("find_kg_paths",
+			mcp.WithDescription(`
+				Find how two knowledge graph entities are related, e.g. a drug and an adverse effect. 
+				Both names are resolved via the fulltext index, preferring exact name or alias matches, and the resolved 
+				entities are returned as from and to. Returns the k shortest relation chains between them 
+				as ordered node and relation lists (relations[i] connects nodes[i] and nodes[i+1]).
+			`),
+			mcp.WithString("from",
+				mcp.Required(),
+				mcp.Description("Start entity name"),
+			),
+			mcp.WithString("to",
+				mcp.Required(),
+				mcp.Description("End entity name"),
+			),
+			mcp.WithNumber("max_length",
+				mcp.Min(1),
+				mcp.Max(4),
+				mcp.Description("Maximum number of relations in a path (1-4, default 3)"),
+			),
+			mcp.WithNumber("k",
+				mcp.Min(1),
+				mcp.Max(10),
+				mcp.Description("Number of shortest paths to return (1-10, default 3)"),
+			),
+			mcp.WithArray("relation_types",
+				mcp.WithStringEnumItems(model.DiaKGRelationTypes),
+				mcp.Description("Only traverse relations of these DiaKG types, e.g. Drug_Disease, ADE_Drug"),
+			),
+		),
+		t.FindKGPaths,
+	)
+
+	s.AddTool(
+		mcp.NewTool("search_guideline_text",
+			mcp.WithDescription(`
+				Search the original diabetes guideline text for sentences and paragraphs relevant to a question, 
+				ranked by fulltext relevance (and semantic similarity when embeddings are available). 
+				Each passage includes its document id, paragraph id, sentence id and the knowledge graph entities 
+				annotated in it; quote these passages to ground answers in the guideline.
+			`),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("Natural language question or keywords, e.g. 二甲双胍的禁忌症"),
+			),
+			mcp.WithString("level",
+				mcp.Enum(tools.PassageLevels...),
+				mcp.Description("Return sentences, paragraphs, or both (default all)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Min(1),
+				mcp.Max(20),
+				mcp.Description("Maximum number of passages to return (1-20, default 5)"),
+			),
+		),
+		t.SearchGuidelineText,
+	)
+
+	s.AddTool(
+		mcp.NewTool("check_medication_safety",
+			mcp.WithDescription(`
+				Check the user's medications against the knowledge graph. Parses the medication, allergy and 
+				complication fields of the health profile together with the drugs of active medication regimens, 
+				resolves each drug to a KG Drug entity, and reports its known adverse effects, disease relations 
+				that overlap the user's complications, and allergy entries that mention the drug, each with 
+				supporting guideline sentences. Medication entries that cannot be resolved are listed as 
+				unresolved; if the regimens cannot be read, only profile medications are checked and 
+				regimens_unavailable is set. The report is informational, not a prescription check.
+			`),
+		),
+		t.CheckMedicationSafety,
 	)
 
 	s.AddTool(
 		mcp.NewTool("fetch_health_data",
-			mcp.WithDescription("Get user health data including blood glucose records, health profile, and exercise records."),
+			mcp.WithDescription(`
+				Get user health data including blood glucose records, health profile, exercise records, 
+				medication regimens and medication dose logs.
+				Records are returned newest first in pages; pass next_cursor from the previous response as cursor to fetch the next page.
+				Legacy glucose records stored without a unit are converted using a unit inferred from magnitude and marked unit_inferred.
+			`),
 			mcp.WithString("type",
 				mcp.Required(),
-				mcp.Enum("blood_glucose", "health_profile", "exercise_records"),
+				mcp.Enum("blood_glucose", "health_profile", "exercise_records", "medications", "medication_doses"),
 				mcp.Description("Type of health data to retrieve"),
 			),
 			mcp.WithNumber("limit",
-				mcp.Description("Number of most recent records to return (10-100, only for blood_glucose and exercise_records)"),
-				mcp.Min(10),
+				mcp.Description("Number of records per page (1-100, only for blood_glucose, exercise_records and medication_doses)"),
+				mcp.Min(1),
 				mcp.Max(100),
 			),
+			mcp.WithString("start",
+				mcp.Description("Only return records at or after this time. RFC3339 timestamp or relative time before now such as 12h, 7d, 2w"),
+			),
+			mcp.WithString("end",
+				mcp.Description("Only return records before this time. RFC3339 timestamp or relative time before now such as 12h, 7d, 2w"),
+			),
+			mcp.WithString("dining_status",
+				mcp.Enum(tools.DiningStatuses...),
+				mcp.Description("Filter blood glucose records by meal context"),
+			),
+			mcp.WithString("exercise_type",
+				mcp.Description("Filter exercise records by exercise type"),
+			),
+			mcp.WithNumber("regimen_id",
+				mcp.Description("Filter medication dose logs by regimen id"),
+			),
+			mcp.WithString("cursor",
+				mcp.Description("Opaque pagination cursor returned as next_cursor by a previous call"),
+			),
+			withGlucoseUnit(),
+		),
+		t.FetchHealthData,
+	)
+
+	s.AddTool(
+		mcp.NewTool("record_blood_glucose",
+			mcp.WithDescription("Record a blood glucose reading for the current user."),
+			mcp.WithNumber("value",
+				mcp.Required(),
+				mcp.Description("Blood glucose value (1.1-33.3 mmol/L or 20-600 mg/dL)"),
+			),
+			withGlucoseUnit(),
+			mcp.WithString("dining_status",
+				mcp.Required(),
+				mcp.Enum(tools.DiningStatuses...),
+				mcp.Description("Meal context of the reading"),
+			),
+			mcp.WithString("measured_at",
+				mcp.Description("Measurement time in RFC3339 format, defaults to now"),
+			),
+		),
+		t.RecordBloodGlucose,
+	)
+
+	s.AddTool(
+		mcp.NewTool("record_exercise",
+			mcp.WithDescription("Record an exercise session for the current user. Duration is derived from start_at and end_at."),
+			mcp.WithString("type",
+				mcp.Required(),
+				mcp.Description("Exercise category, e.g. aerobic, resistance, flexibility"),
+			),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Exercise name, e.g. brisk walking, swimming"),
+			),
+			mcp.WithString("intensity",
+				mcp.Required(),
+				mcp.Enum(tools.ExerciseIntensities...),
+				mcp.Description("Exercise intensity"),
+			),
+			mcp.WithString("start_at",
+				mcp.Required(),
+				mcp.Description("Start time in RFC3339 format"),
+			),
+			mcp.WithString("end_at",
+				mcp.Required(),
+				mcp.Description("End time in RFC3339 format, must be later than start_at"),
+			),
+			mcp.WithNumber("pre_glucose",
+				mcp.Description("Blood glucose before exercise"),
+			),
+			mcp.WithNumber("post_glucose",
+				mcp.Description("Blood glucose after exercise"),
+			),
+			withGlucoseUnit(),
+			mcp.WithString("notes",
+				mcp.Description("Additional notes"),
+			),
 		),
-		tools.FetchHealthData,
+		t.RecordExercise,
+	)
+
+	s.AddTool(
+		mcp.NewTool("update_health_profile",
+			mcp.WithDescription("Update fields of the current user's health profile. Only the provided fields are changed; the profile is created if it does not exist."),
+			mcp.WithString("gender", mcp.Enum(tools.Genders...)),
+			mcp.WithNumber("age", mcp.Min(1), mcp.Max(120)),
+			mcp.WithNumber("height", mcp.Min(50), mcp.Max(250), mcp.Description("Height in cm")),
+			mcp.WithNumber("weight", mcp.Min(10), mcp.Max(300), mcp.Description("Weight in kg")),
+			mcp.WithString("dietary_preference", mcp.Enum(tools.DietaryPreferences...)),
+			mcp.WithBoolean("smoking_status", mcp.Description("Whether the user smokes")),
+			mcp.WithString("activity_level", mcp.Enum(tools.ActivityLevels...)),
+			mcp.WithString("diabetes_type", mcp.Enum(tools.DiabetesTypes...)),
+			mcp.WithNumber("diagnosis_year", mcp.Min(1900)),
+			mcp.WithString("therapy_mode", mcp.Enum(tools.TherapyModes...)),
+			mcp.WithString("medication", mcp.Description("Current medications")),
+			mcp.WithString("allergies", mcp.Description("Known allergies")),
+			mcp.WithString("complications", mcp.Description("Diabetes complications")),
+			mcp.WithString("glucose_unit", mcp.Enum(model.GlucoseUnits...), mcp.Description("Preferred blood glucose unit")),
+		),
+		t.UpdateHealthProfile,
+	)
+
+	s.AddTool(
+		mcp.NewTool("add_medication",
+			mcp.WithDescription(`
+				Add a medication to the current user's regimen. The drug is linked to a knowledge graph Drug entity 
+				when its name or alias matches one exactly. The regimen is active from start_date until stop_date (exclusive).
+			`),
+			mcp.WithString("drug",
+				mcp.Required(),
+				mcp.Description("Drug name, e.g. 二甲双胍"),
+			),
+			mcp.WithNumber("dose",
+				mcp.Required(),
+				mcp.Description("Amount per dose"),
+			),
+			mcp.WithString("unit",
+				mcp.Required(),
+				mcp.Description("Dose unit, e.g. mg, g, IU, tablet"),
+			),
+			mcp.WithString("frequency",
+				mcp.Required(),
+				mcp.Enum(model.MedicationFrequencies...),
+				mcp.Description("Dosing frequency; as_needed doses are excluded from adherence rates"),
+			),
+			mcp.WithString("route",
+				mcp.Enum(tools.MedicationRoutes...),
+				mcp.Description("Route of administration (default oral)"),
+			),
+			mcp.WithString("start_date",
+				mcp.Description("First day of the regimen in YYYY-MM-DD format, defaults to today"),
+			),
+			mcp.WithString("stop_date",
+				mcp.Description("Day the regimen stops in YYYY-MM-DD format, omit if ongoing"),
+			),
+			mcp.WithString("notes",
+				mcp.Description("Additional notes, e.g. take with meals"),
+			),
+		),
+		t.AddMedication,
+	)
+
+	s.AddTool(
+		mcp.NewTool("stop_medication",
+			mcp.WithDescription("Stop a medication regimen of the current user. Doses can no longer be logged from stop_date on."),
+			mcp.WithNumber("regimen_id",
+				mcp.Required(),
+				mcp.Description("Regimen id returned by add_medication or fetch_health_data"),
+			),
+			mcp.WithString("stop_date",
+				mcp.Description("Day the regimen stops in YYYY-MM-DD format, defaults to today"),
+			),
+		),
+		t.StopMedication,
+	)
+
+	s.AddTool(
+		mcp.NewTool("log_medication_dose",
+			mcp.WithDescription("Log a dose of a medication regimen as taken, taken late, or skipped."),
+			mcp.WithNumber("regimen_id",
+				mcp.Required(),
+				mcp.Description("Regimen id returned by add_medication or fetch_health_data"),
+			),
+			mcp.WithString("status",
+				mcp.Required(),
+				mcp.Enum(model.DoseStatuses...),
+				mcp.Description("Whether the dose was taken on time, taken late, or skipped"),
+			),
+			mcp.WithString("taken_at",
+				mcp.Description("Time the dose was taken (or was due, if skipped) in RFC3339 format, defaults to now"),
+			),
+			mcp.WithString("notes",
+				mcp.Description("Additional notes, e.g. reason for skipping"),
+			),
+		),
+		t.LogMedicationDose,
+	)
+
+	s.AddTool(
+		mcp.NewTool("adherence_report",
+			mcp.WithDescription(`
+				Compute per-drug medication adherence over a time window. Expected doses are derived from each regimen's 
+				frequency and the days it was active in the window; the report gives taken, late, skipped and missing 
+				counts, adherence (taken or late, %) and on-time rate (%) per regimen, plus overall adherence.
+			`),
+			mcp.WithString("start",
+				mcp.Description("Window start, RFC3339 timestamp or relative time before now such as 7d. Defaults to 30 days before end"),
+			),
+			mcp.WithString("end",
+				mcp.Description("Window end, RFC3339 timestamp or relative time before now. Defaults to now"),
+			),
+			mcp.WithNumber("regimen_id",
+				mcp.Description("Only report this regimen"),
+			),
+		),
+		t.AdherenceReport,
+	)
+
+	s.AddTool(
+		mcp.NewTool("glucose_statistics",
+			mcp.WithDescription(`
+				Compute glycemic statistics over a time window: mean, SD, coefficient of variation, 
+				time below/in/above range, glucose management indicator (estimated HbA1c), hypo/hyper event counts 
+				and hourly AGP percentile bands (5/25/50/75/95), overall and broken down by dining status.
+				Glucose values are in the requested unit, defaulting to the user's preferred unit or mmol/L.
+				Legacy readings stored without a unit use a unit inferred from magnitude and are counted in inferred_unit_readings.
+			`),
+			mcp.WithString("start",
+				mcp.Description("Window start, RFC3339 timestamp or relative time before now such as 7d. Defaults to 14 days before end"),
+			),
+			mcp.WithString("end",
+				mcp.Description("Window end, RFC3339 timestamp or relative time before now. Defaults to now"),
+			),
+			mcp.WithNumber("target_low",
+				mcp.Description("Lower bound of target range (default 3.9 mmol/L, 70 mg/dL)"),
+			),
+			mcp.WithNumber("target_high",
+				mcp.Description("Upper bound of target range (default 10.0 mmol/L, 180 mg/dL)"),
+			),
+			mcp.WithNumber("very_low",
+				mcp.Description("Level 2 hypoglycemia threshold (default 3.0 mmol/L, 54 mg/dL)"),
+			),
+			mcp.WithNumber("very_high",
+				mcp.Description("Level 2 hyperglycemia threshold (default 13.9 mmol/L, 250 mg/dL)"),
+			),
+			withGlucoseUnit(),
+		),
+		t.GlucoseStatistics,
+	)
+
+	s.AddTool(
+		mcp.NewTool("glucose_events",
+			mcp.WithDescription(`
+				Detect hypoglycemia and hyperglycemia episodes in a time window. Consecutive out-of-range readings are grouped 
+				into one episode with level (1-3), start, end, duration, nadir/peak and reading count. 
+				Nocturnal episodes (00:00-06:00) are flagged and hypo episodes during or shortly after exercise include the exercise record.
+				Glucose values are in the requested unit, defaulting to the user's preferred unit or mmol/L.
+				Episodes containing legacy readings stored without a unit are marked unit_inferred.
+			`),
+			mcp.WithString("start",
+				mcp.Description("Window start, RFC3339 timestamp or relative time before now such as 7d. Defaults to 14 days before end"),
+			),
+			mcp.WithString("end",
+				mcp.Description("Window end, RFC3339 timestamp or relative time before now. Defaults to now"),
+			),
+			mcp.WithNumber("target_low",
+				mcp.Description("Level 1 hypoglycemia threshold (default 3.9 mmol/L, 70 mg/dL)"),
+			),
+			mcp.WithNumber("target_high",
+				mcp.Description("Level 1 hyperglycemia threshold (default 10.0 mmol/L, 180 mg/dL)"),
+			),
+			mcp.WithNumber("very_low",
+				mcp.Description("Level 2 hypoglycemia threshold (default 3.0 mmol/L, 54 mg/dL)"),
+			),
+			mcp.WithNumber("very_high",
+				mcp.Description("Level 2 hyperglycemia threshold (default 13.9 mmol/L, 250 mg/dL)"),
+			),
+			mcp.WithNumber("max_gap_minutes",
+				mcp.Min(1),
+				mcp.Description("Maximum minutes between out-of-range readings in the same episode (default 120)"),
+			),
+			mcp.WithNumber("exercise_window_hours",
+				mcp.Min(0),
+				mcp.Description("Hours after an exercise ends during which a hypo episode is considered exercise-associated (default 6)"),
+			),
+			withGlucoseUnit(),
+		),
+		t.GlucoseEvents,
+	)
+
+	s.AddTool(
+		mcp.NewTool("exercise_glucose_impact",
+			mcp.WithDescription(`
+				Analyze how exercise affects the user's blood glucose. Sessions are grouped by exercise type and intensity; 
+				each group reports average pre/post exercise glucose and delta, readings within the post-exercise window 
+				and the share of sessions followed by delayed hypoglycemia. Groups are sorted by average glucose drop, largest first.
+				Glucose values are in the requested unit, defaulting to the user's preferred unit or mmol/L.
+				Legacy sessions and readings stored without a unit are counted in inferred_unit_records.
+			`),
+			mcp.WithString("start",
+				mcp.Description("Window start, RFC3339 timestamp or relative time before now such as 30d. Defaults to 30 days before end"),
+			),
+			mcp.WithString("end",
+				mcp.Description("Window end, RFC3339 timestamp or relative time before now. Defaults to now"),
+			),
+			mcp.WithString("exercise_type",
+				mcp.Description("Only analyze exercises of this type"),
+			),
+			mcp.WithNumber("post_window_hours",
+				mcp.Min(0),
+				mcp.Max(48),
+				mcp.Description("Hours after each session in which glucose readings are attributed to it (default 24)"),
+			),
+			mcp.WithNumber("hypo_threshold",
+				mcp.Description("Glucose below this value counts as delayed hypoglycemia (default 3.9 mmol/L, 70 mg/dL)"),
+			),
+			withGlucoseUnit(),
+		),
+		t.ExerciseGlucoseImpact,
+	)
+}
+
+// 所有读写血糖值的工具共用的单位参数
+func withGlucoseUnit() mcp.ToolOption {
+	return mcp.WithString("unit",
+		mcp.Enum(model.GlucoseUnits...),
+		mcp.Description("Blood glucose unit. Defaults to the user's preferred unit, otherwise mmol/L"),
 	)
 }
@@ -3,134 +3,265 @@ package tools
 import (
 	"context"
 	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
 	"fmt"
 	"log/slog"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/mitchellh/mapstructure"
-	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 const (
-	Neo4jFulltextIndexName = "fulltext_index_entity_name"
-
 	defaultSearchResultLimit = 20
-)
 
-type KnowlegeGraphSearchResult struct {
-	Node          EntityNode `json:"node"`
-	Relationships []Relation `json:"relationships"`
-	Score         float32    `json:"score"`
-}
+	maxEntityHops        = 3
+	defaultRelationLimit = 50
+	defaultEvidenceLimit = 10
 
-type EntityNode struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-}
+	defaultPathLength = 3
+	maxPathLength     = 4
+	defaultPathCount  = 3
+	maxPathCount      = 10
+	// 解析路径端点时检查的全文检索候选数
+	resolveCandidateLimit = 10
+
+	defaultPassageLimit = 5
+	maxPassageLimit     = 20
+)
+
+// PassageLevels 原文检索的粒度，all 同时返回句子和段落
+var PassageLevels = []string{"all", model.PassageLevelSentence, model.PassageLevelParagraph}
 
-type Relation struct {
-	Type    string     `json:"type"`
-	Related EntityNode `json:"related"`
+type kgSearchResult struct {
+	// DetectedTerms 服务端在查询中识别出的实体名称
+	DetectedTerms []model.QueryTerm `json:"detected_terms"`
+	// Keywords 实际用于检索的关键词
+	Keywords []string                          `json:"keywords"`
+	Results  []model.KnowlegeGraphSearchResult `json:"results"`
 }
 
-// SearchDiabetesKnowledgeGraph 检索基于 DiaKG 构建的图数据库
-func SearchDiabetesKnowledgeGraph(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// SearchDiabetesKnowledgeGraph 检索基于 DiaKG 构建的知识图谱
+func (t *Tools) SearchDiabetesKnowledgeGraph(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	query := req.GetString("query", "")
 	if query == "" {
 		return mcp.NewToolResultError("query param is required"), nil
 	}
 
-	keywords := strings.Split(query, " ")
-	limit := req.GetInt("limit", defaultSearchResultLimit)
+	opts := dao.SearchOptions{
+		Limit: req.GetInt("limit", defaultSearchResultLimit),
+		Query: query,
+	}
+	var err error
+	if opts.EntityTypes, err = parseEnumSlice(req, "entity_types", model.DiaKGEntityTypes); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if opts.RelationTypes, err = parseEnumSlice(req, "relation_types", model.DiaKGRelationTypes); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
-	results, err := executeFulltextSearch(ctx, keywords, limit)
+	keywords, terms, err := t.extractKeywords(ctx, query)
+	if err != nil {
+		slog.Error("Failed to load entity dictionary", "err", err)
+		return mcp.NewToolResultError("failed to search knowledge graph"), nil
+	}
+	if len(keywords) == 0 {
+		return mcp.NewToolResultError("no keywords found in query"), nil
+	}
+
+	results, err := t.graph.SearchEntities(ctx, keywords, opts)
 	if err != nil {
 		slog.Error("Failed to search knowledge graph", "err", err)
+		return mcp.NewToolResultError("failed to search knowledge graph"), nil
 	}
+	if results == nil {
+		results = []model.KnowlegeGraphSearchResult{}
+	}
+	for i := range results {
+		if results[i].MatchedAlias == "" {
+			results[i].MatchedAlias = aliasInTerms(results[i].Node, terms)
+		}
+	}
+
+	return mcp.NewToolResultJSON(kgSearchResult{
+		DetectedTerms: terms,
+		Keywords:      keywords,
+		Results:       results,
+	})
+}
 
-	return mcp.NewToolResultJSON(results)
+type guidelinePassagesResult struct {
+	DetectedTerms []model.QueryTerm `json:"detected_terms"`
+	Keywords      []string          `json:"keywords"`
+	Passages      []model.Passage   `json:"passages"`
 }
 
-// 执行全文搜索，根据 keywords 模糊匹配 Entity 节点的 name 属性
-func executeFulltextSearch(ctx context.Context, keywords []string, limit int) ([]KnowlegeGraphSearchResult, error) {
-	session := dao.Driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
-	defer session.Close(ctx)
+// SearchGuidelineText 检索指南原文中与查询相关的句子和段落，附带其中标注的实体，供回答引用
+func (t *Tools) SearchGuidelineText(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	query, err := req.RequireString("query")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	level := req.GetString("level", "all")
+	if err := validateEnum("level", level, PassageLevels); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	opts := dao.PassageOptions{
+		Limit: req.GetInt("limit", defaultPassageLimit),
+		Query: query,
+	}
+	if opts.Limit < 1 || opts.Limit > maxPassageLimit {
+		return mcp.NewToolResultErrorf("limit must be between 1 and %d", maxPassageLimit), nil
+	}
+	if level != "all" {
+		opts.Level = level
+	}
 
-	keywords = cleanKeywords(keywords)
+	keywords, terms, err := t.extractKeywords(ctx, query)
+	if err != nil {
+		slog.Error("Failed to load entity dictionary", "err", err)
+		return mcp.NewToolResultError("failed to search guideline text"), nil
+	}
 	if len(keywords) == 0 {
-		return nil, fmt.Errorf("valid keywords not found")
-	}
-
-	// 构建模糊查询条件
-	query := strings.Join(keywords, " OR ")
-
-	// 返回匹配查询且至少存在一个关系的节点
-	cypherQuery := `
-        CALL db.index.fulltext.queryNodes($indexName, $query) 
-        YIELD node, score
-        WHERE 'Entity' IN labels(node)
-        MATCH (node)-[r]-(related:Entity)
-        WITH node, score, collect({
-            type: type(r),
-            related: related {.name, .type}
-        }) AS relationships
-        RETURN 
-            node {.name, .type} AS node,
-            relationships,
-            score
-        ORDER BY score DESC
-        LIMIT $limit
-    `
-
-	result, err := session.Run(ctx, cypherQuery, map[string]any{
-		"indexName": Neo4jFulltextIndexName,
-		"query":     query,
-		"limit":     limit,
+		return mcp.NewToolResultError("no keywords found in query"), nil
+	}
+
+	passages, err := t.graph.SearchPassages(ctx, keywords, opts)
+	if err != nil {
+		slog.Error("Failed to search guideline text", "err", err)
+		return mcp.NewToolResultError("failed to search guideline text"), nil
+	}
+	if passages == nil {
+		passages = []model.Passage{}
+	}
+
+	return mcp.NewToolResultJSON(guidelinePassagesResult{
+		DetectedTerms: terms,
+		Keywords:      keywords,
+		Passages:      passages,
 	})
+}
+
+// GetEntityDetails 返回实体的多跳关系及其在指南原文中的出处
+func (t *Tools) GetEntityDetails(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	key, err := req.RequireString("entity")
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute fulltext query: %v", err)
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	// 将 map 转换为结构体
-	var results []KnowlegeGraphSearchResult
-	for result.Next(ctx) {
-		var sr KnowlegeGraphSearchResult
-		if err := mapstructure.Decode(result.Record().AsMap(), &sr); err != nil {
-			return nil, fmt.Errorf("failed to decode search result: %v", err)
-		}
-		results = append(results, sr)
+	opts := dao.EntityDetailsOptions{
+		Hops:          req.GetInt("hops", 1),
+		RelationLimit: req.GetInt("relation_limit", defaultRelationLimit),
+		EvidenceLimit: req.GetInt("evidence_limit", defaultEvidenceLimit),
+	}
+	if opts.Hops < 1 || opts.Hops > maxEntityHops {
+		return mcp.NewToolResultErrorf("hops must be between 1 and %d", maxEntityHops), nil
+	}
+	if opts.RelationLimit < 1 || opts.EvidenceLimit < 1 {
+		return mcp.NewToolResultError("relation_limit and evidence_limit must be positive"), nil
 	}
 
-	if err = result.Err(); err != nil {
-		return nil, fmt.Errorf("failed to process search results: %v", err)
+	details, err := t.graph.GetEntityDetails(ctx, key, opts)
+	if err != nil {
+		slog.Error("Failed to get entity details",
+			"entity", key,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get entity details"), nil
+	}
+	if details == nil {
+		return mcp.NewToolResultErrorf("entity %q not found", key), nil
 	}
 
-	return results, nil
+	return mcp.NewToolResultJSON(details)
 }
 
-func cleanKeywords(keywords []string) []string {
-	var escapedKeywords []string
-	for _, k := range keywords {
-		if k == "" {
-			continue
-		}
-		cleanK := sanitizeLuceneQuery(k)
-		if cleanK != "" {
-			escapedKeywords = append(escapedKeywords, cleanK)
-		}
+type kgPathsResult struct {
+	From  *model.EntityNode `json:"from"`
+	To    *model.EntityNode `json:"to"`
+	Paths []model.Path      `json:"paths"`
+}
+
+// FindKGPaths 查找两个实体之间的关系链
+func (t *Tools) FindKGPaths(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	fromQuery, err := req.RequireString("from")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
-	return escapedKeywords
+	toQuery, err := req.RequireString("to")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts := dao.PathOptions{
+		MaxLength: req.GetInt("max_length", defaultPathLength),
+		K:         req.GetInt("k", defaultPathCount),
+	}
+	if opts.RelationTypes, err = parseEnumSlice(req, "relation_types", model.DiaKGRelationTypes); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if opts.MaxLength < 1 || opts.MaxLength > maxPathLength {
+		return mcp.NewToolResultErrorf("max_length must be between 1 and %d", maxPathLength), nil
+	}
+	if opts.K < 1 || opts.K > maxPathCount {
+		return mcp.NewToolResultErrorf("k must be between 1 and %d", maxPathCount), nil
+	}
+
+	from, err := t.resolveEntity(ctx, fromQuery)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	to, err := t.resolveEntity(ctx, toQuery)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	paths, err := t.graph.FindPaths(ctx, from.ID, to.ID, opts)
+	if err != nil {
+		slog.Error("Failed to find knowledge graph paths",
+			"from", from.ID,
+			"to", to.ID,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to find knowledge graph paths"), nil
+	}
+	if paths == nil {
+		paths = []model.Path{}
+	}
+
+	return mcp.NewToolResultJSON(kgPathsResult{
+		From:  from,
+		To:    to,
+		Paths: paths,
+	})
 }
 
-// 转义 Lucene 保留字符
-func sanitizeLuceneQuery(query string) string {
-	replacer := strings.NewReplacer(
-		"+", "\\+", "-", "\\-", "&", "\\&", "|", "\\|",
-		"!", "\\!", "(", "\\(", ")", "\\)", "{", "\\{",
-		"}", "\\}", "[", "\\[", "]", "\\]", "^", "\\^",
-		"\"", "\\\"", "~", "\\~", "*", "\\*", "?", "\\?",
-		":", "\\:", "\\", "\\\\", "/", "\\/",
-	)
-	return replacer.Replace(query)
+// 通过全文检索将名称解析为图谱中的实体：候选中名称或别名与之完全一致的优先，否则取得分最高的实体
+func (t *Tools) resolveEntity(ctx context.Context, name string) (*model.EntityNode, error) {
+	results, err := t.graph.SearchEntities(ctx, strings.Fields(name), dao.SearchOptions{
+		Limit: resolveCandidateLimit,
+		Query: name,
+	})
+	if err != nil {
+		slog.Error("Failed to search knowledge graph", "name", name, "err", err)
+		return nil, fmt.Errorf("failed to resolve entity %q", name)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("entity %q not found", name)
+	}
+
+	normalized := model.NormalizeEntityName(name)
+	for _, r := range results {
+		if model.NormalizeEntityName(r.Node.Name) == normalized {
+			return &r.Node, nil
+		}
+	}
+	for _, r := range results {
+		for _, alias := range r.Node.Aliases {
+			if model.NormalizeEntityName(alias) == normalized {
+				return &r.Node, nil
+			}
+		}
+	}
+	return &results[0].Node, nil
 }
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/analysis"
+	"diabetes-care-mcp-server/dao"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	defaultImpactWindow          = 30 * 24 * time.Hour
+	defaultPostExerciseWindowHrs = 24
+)
+
+// ExerciseGlucoseImpact 按运动类型和强度分析运动对血糖的影响
+func (t *Tools) ExerciseGlucoseImpact(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start, end, err := parseWindow(req, defaultImpactWindow)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	unit, err := t.resolveGlucoseUnit(ctx, email, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts := analysis.ExerciseImpactOptions{
+		PostWindow:    time.Duration(req.GetInt("post_window_hours", defaultPostExerciseWindowHrs)) * time.Hour,
+		HypoThreshold: req.GetFloat("hypo_threshold", analysis.DefaultGlucoseThresholds(unit).Low),
+	}
+	if opts.PostWindow < 0 {
+		return mcp.NewToolResultError("post_window_hours must not be negative"), nil
+	}
+
+	exercises, err := t.getExerciseRecords(ctx, email, dao.RecordQuery{
+		Start:        start,
+		End:          end,
+		ExerciseType: req.GetString("exercise_type", ""),
+	}, unit)
+	if err != nil {
+		slog.Error("Failed to get exercise records",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get exercise records"), nil
+	}
+
+	// 最后一次运动之后的窗口可能超出统计区间
+	records, err := t.getBloodGlucoseRecords(ctx, email, dao.RecordQuery{
+		Start: start,
+		End:   end.Add(opts.PostWindow),
+	}, unit)
+	if err != nil {
+		slog.Error("Failed to get blood glucose records",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get blood glucose records"), nil
+	}
+
+	return mcp.NewToolResultJSON(analysis.ExerciseImpactReport{
+		Unit:            unit,
+		Start:           start,
+		End:             end,
+		PostWindowHours: opts.PostWindow.Hours(),
+		Activities:      analysis.AnalyzeExerciseImpact(exercises, records, opts),
+	})
+}
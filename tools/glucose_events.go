@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/analysis"
+	"diabetes-care-mcp-server/dao"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const defaultExerciseWindowHours = 6
+
+// GlucoseEvents 识别窗口内的低/高血糖事件
+func (t *Tools) GlucoseEvents(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start, end, err := parseWindow(req, defaultStatisticsWindow)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	unit, err := t.resolveGlucoseUnit(ctx, email, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	thresholds, err := parseGlucoseThresholds(req, unit)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	opts := analysis.EpisodeOptions{
+		Thresholds:     thresholds,
+		MaxGap:         time.Duration(req.GetInt("max_gap_minutes", int(analysis.DefaultEpisodeGap.Minutes()))) * time.Minute,
+		ExerciseWindow: time.Duration(req.GetInt("exercise_window_hours", defaultExerciseWindowHours)) * time.Hour,
+	}
+	if opts.MaxGap <= 0 || opts.ExerciseWindow < 0 {
+		return mcp.NewToolResultError("max_gap_minutes must be positive and exercise_window_hours must not be negative"), nil
+	}
+
+	records, err := t.getBloodGlucoseRecords(ctx, email, dao.RecordQuery{Start: start, End: end}, unit)
+	if err != nil {
+		slog.Error("Failed to get blood glucose records",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get blood glucose records"), nil
+	}
+
+	// 窗口起点之前开始的运动也可能与窗口内的低血糖有关
+	exercises, err := t.getExerciseRecords(ctx, email, dao.RecordQuery{
+		Start: start.Add(-opts.ExerciseWindow - 24*time.Hour),
+		End:   end,
+	}, unit)
+	if err != nil {
+		slog.Error("Failed to get exercise records",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get exercise records"), nil
+	}
+
+	return mcp.NewToolResultJSON(analysis.DetectGlucoseEvents(records, exercises, opts, start, end))
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/analysis"
+	"diabetes-care-mcp-server/dao"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// AGP 报告推荐的默认统计窗口
+const defaultStatisticsWindow = 14 * 24 * time.Hour
+
+// GlucoseStatistics 统计窗口内的血糖指标
+func (t *Tools) GlucoseStatistics(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start, end, err := parseWindow(req, defaultStatisticsWindow)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	unit, err := t.resolveGlucoseUnit(ctx, email, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	thresholds, err := parseGlucoseThresholds(req, unit)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	records, err := t.getBloodGlucoseRecords(ctx, email, dao.RecordQuery{Start: start, End: end}, unit)
+	if err != nil {
+		slog.Error("Failed to get blood glucose records",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get blood glucose records"), nil
+	}
+
+	return mcp.NewToolResultJSON(analysis.ComputeGlucoseStatistics(records, thresholds, start, end))
+}
+
+// 解析统计窗口，未指定 start 时取 end 之前的 defaultWindow，未指定 end 时取当前时间
+func parseWindow(req mcp.CallToolRequest, defaultWindow time.Duration) (time.Time, time.Time, error) {
+	now := time.Now()
+
+	end, err := parseTimeParam(req.GetString("end", ""), now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %w", err)
+	}
+	if end.IsZero() {
+		end = now
+	}
+
+	start, err := parseTimeParam(req.GetString("start", ""), now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %w", err)
+	}
+	if start.IsZero() {
+		start = end.Add(-defaultWindow)
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start must be earlier than end")
+	}
+	return start, end, nil
+}
+
+// 解析 unit 单位下的血糖阈值，未传入的使用默认值
+func parseGlucoseThresholds(req mcp.CallToolRequest, unit string) (analysis.GlucoseThresholds, error) {
+	thresholds := analysis.DefaultGlucoseThresholds(unit)
+	thresholds.VeryLow = req.GetFloat("very_low", thresholds.VeryLow)
+	thresholds.Low = req.GetFloat("target_low", thresholds.Low)
+	thresholds.High = req.GetFloat("target_high", thresholds.High)
+	thresholds.VeryHigh = req.GetFloat("very_high", thresholds.VeryHigh)
+
+	if !(thresholds.VeryLow < thresholds.Low && thresholds.Low < thresholds.High && thresholds.High < thresholds.VeryHigh) {
+		return thresholds, fmt.Errorf("thresholds must satisfy very_low < target_low < target_high < very_high")
+	}
+	return thresholds, nil
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// unitResult 带血糖单位的单条记录
+type unitResult[T any] struct {
+	Record T      `json:"record"`
+	Unit   string `json:"unit"`
+}
+
+// 确定返回结果使用的血糖单位：请求参数 > 健康档案中的偏好 > mmol/L
+func (t *Tools) resolveGlucoseUnit(ctx context.Context, email string, req mcp.CallToolRequest) (string, error) {
+	if unit := req.GetString("unit", ""); unit != "" {
+		if err := validateEnum("unit", unit, model.GlucoseUnits); err != nil {
+			return "", err
+		}
+		return unit, nil
+	}
+
+	profile, err := t.healthData.GetHealthProfile(ctx, email)
+	if err != nil {
+		slog.Error("Failed to get health profile",
+			"email", email,
+			"err", err,
+		)
+		return "", fmt.Errorf("failed to get health profile")
+	}
+	if profile != nil && profile.GlucoseUnit != "" {
+		return profile.GlucoseUnit, nil
+	}
+
+	return model.GlucoseUnitMmolL, nil
+}
+
+// 查询血糖记录，并将数值换算为 unit
+func (t *Tools) getBloodGlucoseRecords(ctx context.Context, email string, query dao.RecordQuery, unit string) ([]model.BloodGlucoseRecord, error) {
+	records, err := t.healthData.GetBloodGlucoseRecords(ctx, email, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		r := &records[i]
+		r.Value, r.UnitInferred = toUnit(r.Value, r.GlucoseUnit, unit)
+		r.GlucoseUnit = unit
+	}
+	return records, nil
+}
+
+// 查询运动记录，并将运动前后血糖换算为 unit
+func (t *Tools) getExerciseRecords(ctx context.Context, email string, query dao.RecordQuery, unit string) ([]model.ExerciseRecord, error) {
+	records, err := t.healthData.GetExerciseRecords(ctx, email, query)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		r := &records[i]
+		var preInferred, postInferred bool
+		r.PreGlucose, preInferred = toUnit(r.PreGlucose, r.GlucoseUnit, unit)
+		r.PostGlucose, postInferred = toUnit(r.PostGlucose, r.GlucoseUnit, unit)
+		r.UnitInferred = preInferred || postInferred
+		r.GlucoseUnit = unit
+	}
+	return records, nil
+}
+
+// 将以 stored 单位存储的血糖值换算为 unit，stored 为空的历史记录按数值推断单位并返回 inferred；
+// 0 表示未记录，原样返回
+func toUnit(value float32, stored, unit string) (float32, bool) {
+	if value == 0 {
+		return 0, false
+	}
+	from, inferred := model.StoredGlucoseUnit(value, stored)
+	return float32(model.RoundGlucose(model.ConvertGlucose(float64(value), from, unit), unit)), inferred
+}
@@ -0,0 +1,311 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// 各单位下血糖值的合法范围，与常见血糖仪的量程一致
+var glucoseValueRange = map[string][2]float64{
+	model.GlucoseUnitMmolL: {1.1, 33.3},
+	model.GlucoseUnitMgdL:  {20, 600},
+}
+
+var (
+	DiningStatuses      = []string{"fasting", "before_meal", "after_meal", "bedtime", "random"}
+	ExerciseIntensities = []string{"low", "medium", "high"}
+	Genders             = []string{"male", "female"}
+	ActivityLevels      = []string{"sedentary", "light", "moderate", "active"}
+	DiabetesTypes       = []string{"type1", "type2", "gestational", "other"}
+	TherapyModes        = []string{"diet", "oral", "insulin", "combined"}
+	DietaryPreferences  = []string{"normal", "low_carb", "low_fat", "vegetarian", "other"}
+)
+
+// RecordBloodGlucose 记录一条血糖数据
+func (t *Tools) RecordBloodGlucose(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	value, err := req.RequireFloat("value")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	unit, err := t.resolveGlucoseUnit(ctx, email, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateGlucose("value", value, unit); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	diningStatus, err := req.RequireString("dining_status")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateEnum("dining_status", diningStatus, DiningStatuses); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	measuredAt := time.Now()
+	if s := req.GetString("measured_at", ""); s != "" {
+		measuredAt, err = parseRecordTime("measured_at", s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	record := model.BloodGlucoseRecord{
+		Value:        float32(model.ConvertGlucose(value, unit, model.GlucoseUnitMmolL)),
+		MeasuredAt:   measuredAt,
+		DiningStatus: diningStatus,
+		GlucoseUnit:  model.GlucoseUnitMmolL,
+	}
+	if err := t.healthData.CreateBloodGlucoseRecord(ctx, email, &record); err != nil {
+		slog.Error("Failed to create blood glucose record",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to save blood glucose record"), nil
+	}
+
+	record.Value = float32(value)
+	record.GlucoseUnit = unit
+	return mcp.NewToolResultJSON(unitResult[model.BloodGlucoseRecord]{Record: record, Unit: unit})
+}
+
+// RecordExercise 记录一次运动，运动时长由起止时间推算
+func (t *Tools) RecordExercise(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	exerciseType, err := req.RequireString("type")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	intensity, err := req.RequireString("intensity")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateEnum("intensity", intensity, ExerciseIntensities); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	startAt, err := requireRecordTime(req, "start_at")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	endAt, err := requireRecordTime(req, "end_at")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !startAt.Before(endAt) {
+		return mcp.NewToolResultError("start_at must be earlier than end_at"), nil
+	}
+
+	record := model.ExerciseRecord{
+		Type:      exerciseType,
+		Name:      name,
+		Intensity: intensity,
+		StartAt:   startAt,
+		EndAt:     endAt,
+		Duration:  exerciseDuration(startAt, endAt),
+		Notes:     req.GetString("notes", ""),
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	pre := req.GetFloat("pre_glucose", 0)
+	post := req.GetFloat("post_glucose", 0)
+	unit, err := t.resolveGlucoseUnit(ctx, email, req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	record.GlucoseUnit = model.GlucoseUnitMmolL
+
+	if pre != 0 {
+		if err := validateGlucose("pre_glucose", pre, unit); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		record.PreGlucose = float32(model.ConvertGlucose(pre, unit, model.GlucoseUnitMmolL))
+	}
+	if post != 0 {
+		if err := validateGlucose("post_glucose", post, unit); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		record.PostGlucose = float32(model.ConvertGlucose(post, unit, model.GlucoseUnitMmolL))
+	}
+
+	if err := t.healthData.CreateExerciseRecord(ctx, email, &record); err != nil {
+		slog.Error("Failed to create exercise record",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to save exercise record"), nil
+	}
+
+	record.PreGlucose = float32(pre)
+	record.PostGlucose = float32(post)
+	record.GlucoseUnit = unit
+	return mcp.NewToolResultJSON(unitResult[model.ExerciseRecord]{Record: record, Unit: unit})
+}
+
+// UpdateHealthProfile 更新健康档案中传入的字段，档案不存在时创建
+func (t *Tools) UpdateHealthProfile(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	updates, err := parseHealthProfileUpdates(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(updates) == 0 {
+		return mcp.NewToolResultError("at least one profile field is required"), nil
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	if err := t.healthData.UpdateHealthProfile(ctx, email, updates); err != nil {
+		slog.Error("Failed to update health profile",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to update health profile"), nil
+	}
+
+	profile, err := t.healthData.GetHealthProfile(ctx, email)
+	if err != nil {
+		slog.Error("Failed to get health profile",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get health profile"), nil
+	}
+
+	return mcp.NewToolResultJSON(profile)
+}
+
+func parseHealthProfileUpdates(req mcp.CallToolRequest) (map[string]any, error) {
+	args := req.GetArguments()
+	updates := make(map[string]any)
+
+	enumFields := map[string][]string{
+		"gender":             Genders,
+		"dietary_preference": DietaryPreferences,
+		"activity_level":     ActivityLevels,
+		"diabetes_type":      DiabetesTypes,
+		"therapy_mode":       TherapyModes,
+		"glucose_unit":       model.GlucoseUnits,
+	}
+	for field, allowed := range enumFields {
+		if _, ok := args[field]; !ok {
+			continue
+		}
+		v := req.GetString(field, "")
+		if err := validateEnum(field, v, allowed); err != nil {
+			return nil, err
+		}
+		updates[field] = v
+	}
+
+	for _, field := range []string{"medication", "allergies", "complications"} {
+		if _, ok := args[field]; ok {
+			updates[field] = req.GetString(field, "")
+		}
+	}
+
+	numberFields := []struct {
+		name     string
+		min, max float64
+		integer  bool
+	}{
+		{"age", 1, 120, true},
+		{"height", 50, 250, false},
+		{"weight", 10, 300, false},
+		{"diagnosis_year", 1900, float64(time.Now().Year()), true},
+	}
+	for _, f := range numberFields {
+		if _, ok := args[f.name]; !ok {
+			continue
+		}
+		v, err := req.RequireFloat(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if v < f.min || v > f.max {
+			return nil, fmt.Errorf("%s must be between %g and %g", f.name, f.min, f.max)
+		}
+		if f.integer {
+			updates[f.name] = int(v)
+		} else {
+			updates[f.name] = v
+		}
+	}
+
+	if _, ok := args["smoking_status"]; ok {
+		v, err := req.RequireBool("smoking_status")
+		if err != nil {
+			return nil, err
+		}
+		updates["smoking_status"] = v
+	}
+
+	return updates, nil
+}
+
+// exerciseDuration 运动时长（分钟），不足一分钟的部分向上取整，start 早于 end 时至少为 1
+func exerciseDuration(start, end time.Time) int {
+	return int(math.Ceil(end.Sub(start).Minutes()))
+}
+
+// 校验 unit 单位下的血糖值是否在合法范围内
+func validateGlucose(field string, value float64, unit string) error {
+	r := glucoseValueRange[unit]
+	if value < r[0] || value > r[1] {
+		return fmt.Errorf("%s must be between %g and %g %s", field, r[0], r[1], unit)
+	}
+	return nil
+}
+
+func validateEnum(field, value string, allowed []string) error {
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("invalid %s %q, must be one of %v", field, value, allowed)
+	}
+	return nil
+}
+
+// 读取字符串数组参数并逐项校验取值
+func parseEnumSlice(req mcp.CallToolRequest, field string, allowed []string) ([]string, error) {
+	values := req.GetStringSlice(field, nil)
+	for _, v := range values {
+		if err := validateEnum(field, v, allowed); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func requireRecordTime(req mcp.CallToolRequest, field string) (time.Time, error) {
+	s, err := req.RequireString(field)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseRecordTime(field, s)
+}
+
+// 解析 RFC3339 时间，不允许晚于当前时间
+func parseRecordTime(field, s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 timestamp", field)
+	}
+	if t.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("%s cannot be in the future", field)
+	}
+	return t, nil
+}
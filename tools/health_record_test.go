@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/dao"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestExerciseDuration(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		end  time.Time
+		want int
+	}{
+		{"under a minute", start.Add(50 * time.Second), 1},
+		{"whole minutes", start.Add(30 * time.Minute), 30},
+		{"partial minute rounded up", start.Add(30*time.Minute + time.Second), 31},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exerciseDuration(start, tt.end); got != tt.want {
+				t.Errorf("exerciseDuration() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordExerciseShortSession(t *testing.T) {
+	const email = "user@example.com"
+	ctx := context.WithValue(context.Background(), "user_email", email)
+	store := dao.NewMemoryHealthDataStore()
+	tools := NewTools(store, nil)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"type":      "aerobic",
+		"name":      "跳绳",
+		"intensity": "high",
+		"start_at":  start.Format(time.RFC3339),
+		"end_at":    start.Add(50 * time.Second).Format(time.RFC3339),
+	}
+
+	result, err := tools.RecordExercise(ctx, req)
+	if err != nil || result.IsError {
+		t.Fatalf("RecordExercise = %+v, %v", result, err)
+	}
+	records, err := store.GetExerciseRecords(ctx, email, dao.RecordQuery{})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("GetExerciseRecords = %v, %v; want one record", records, err)
+	}
+	if records[0].Duration != 1 {
+		t.Errorf("Duration = %d, want 1", records[0].Duration)
+	}
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/analysis"
+	"diabetes-care-mcp-server/model"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// 实体词典的刷新间隔，使服务运行期间新导入的实体也能被识别
+const entityDictionaryTTL = 10 * time.Minute
+
+// entityDictionary 由图谱实体名称构建的词典，首次使用时加载并定期刷新
+type entityDictionary struct {
+	mu       sync.Mutex
+	dict     *analysis.EntityDictionary
+	loadedAt time.Time
+}
+
+func (t *Tools) entityDictionary(ctx context.Context) (*analysis.EntityDictionary, error) {
+	d := &t.dictionary
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.dict != nil && time.Since(d.loadedAt) < entityDictionaryTTL {
+		return d.dict, nil
+	}
+
+	entities, err := t.graph.ListEntities(ctx)
+	if err != nil {
+		// 刷新失败时继续使用旧词典
+		if d.dict != nil {
+			slog.Warn("Failed to refresh entity dictionary", "err", err)
+			return d.dict, nil
+		}
+		return nil, err
+	}
+
+	d.dict = analysis.NewEntityDictionary(entities)
+	d.loadedAt = time.Now()
+	slog.Info("Loaded entity dictionary", "terms", d.dict.Len())
+	return d.dict, nil
+}
+
+// extractKeywords 识别查询中的实体名称和别名，以实体的规范名称作为检索关键词；
+// 不包含任何实体名称的空白分隔片段也作为关键词保留，兼容调用方预先切分的关键词
+func (t *Tools) extractKeywords(ctx context.Context, query string) ([]string, []model.QueryTerm, error) {
+	dict, err := t.entityDictionary(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms := dict.Extract(query)
+	if terms == nil {
+		terms = []model.QueryTerm{}
+	}
+
+	var keywords []string
+	seen := make(map[string]bool)
+	add := func(keyword string) {
+		if key := model.NormalizeEntityName(keyword); !seen[key] {
+			seen[key] = true
+			keywords = append(keywords, keyword)
+		}
+	}
+
+	for _, term := range terms {
+		add(term.Entities[0].Name)
+	}
+	for _, field := range unmatchedFields(query, terms) {
+		add(field)
+	}
+
+	return keywords, terms, nil
+}
+
+// unmatchedFields 返回查询中与所有识别出的实体名称都不重叠的空白分隔片段
+func unmatchedFields(query string, terms []model.QueryTerm) []string {
+	var fields []string
+	runes := []rune(query)
+	for start := 0; start < len(runes); {
+		if unicode.IsSpace(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && !unicode.IsSpace(runes[end]) {
+			end++
+		}
+
+		overlaps := false
+		for _, term := range terms {
+			if term.Start < end && term.End > start {
+				overlaps = true
+				break
+			}
+		}
+		if field := strings.TrimSpace(string(runes[start:end])); !overlaps && field != "" {
+			fields = append(fields, field)
+		}
+		start = end
+	}
+	return fields
+}
+
+// aliasInTerms 查询中以别名而非名称提及该实体时返回查询中的别名文本
+func aliasInTerms(node model.EntityNode, terms []model.QueryTerm) string {
+	name := model.NormalizeEntityName(node.Name)
+	for _, term := range terms {
+		if model.NormalizeEntityName(term.Text) == name {
+			continue
+		}
+		for _, e := range term.Entities {
+			if e.ID == node.ID {
+				return term.Text
+			}
+		}
+	}
+	return ""
+}
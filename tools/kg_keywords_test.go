@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// entityListGraph 只实现 ListEntities 的知识图谱
+type entityListGraph struct {
+	dao.KnowledgeGraph
+	entities []model.EntityNode
+	err      error
+}
+
+func (g *entityListGraph) ListEntities(ctx context.Context) ([]model.EntityNode, error) {
+	return g.entities, g.err
+}
+
+func TestExtractKeywords(t *testing.T) {
+	tools := NewTools(nil, &entityListGraph{entities: []model.EntityNode{
+		{ID: "1", Name: "二甲双胍", Type: "Drug", Aliases: []string{"格华止"}},
+		{ID: "2", Name: "2型糖尿病", Type: "Disease"},
+		{ID: "3", Name: "低血糖", Type: "Symptom"},
+	}})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"entities in cjk text", "二甲双胍治疗2型糖尿病", []string{"二甲双胍", "2型糖尿病"}},
+		{"alias replaced by name", "格华止 副作用", []string{"二甲双胍", "副作用"}},
+		{"unmatched fields kept", "空腹 血糖 控制目标", []string{"空腹", "血糖", "控制目标"}},
+		{"fields overlapping entities dropped", "服用二甲双胍后 头晕", []string{"二甲双胍", "头晕"}},
+		{"duplicates removed", "低血糖 低血糖 二甲双胍 格华止", []string{"低血糖", "二甲双胍"}},
+		{"unmatched field duplicates an entity", "低血糖发作 低血糖", []string{"低血糖"}},
+		{"blank query", " \t ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keywords, terms, err := tools.extractKeywords(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("extractKeywords: %v", err)
+			}
+			if terms == nil {
+				t.Error("terms = nil, want non-nil")
+			}
+			if !slices.Equal(keywords, tt.want) {
+				t.Errorf("keywords = %q, want %q", keywords, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractKeywordsListError(t *testing.T) {
+	graph := &entityListGraph{err: errors.New("unavailable")}
+	tools := NewTools(nil, graph)
+
+	if _, _, err := tools.extractKeywords(context.Background(), "二甲双胍"); err == nil {
+		t.Fatal("extractKeywords succeeded without a dictionary, want error")
+	}
+
+	// 已加载词典时刷新失败继续使用旧词典
+	graph.entities, graph.err = []model.EntityNode{{ID: "1", Name: "二甲双胍", Type: "Drug"}}, nil
+	if _, _, err := tools.extractKeywords(context.Background(), "二甲双胍"); err != nil {
+		t.Fatalf("extractKeywords: %v", err)
+	}
+	graph.entities, graph.err = nil, errors.New("unavailable")
+	tools.dictionary.loadedAt = tools.dictionary.loadedAt.Add(-2 * entityDictionaryTTL)
+	keywords, _, err := tools.extractKeywords(context.Background(), "二甲双胍")
+	if err != nil {
+		t.Fatalf("extractKeywords after failed refresh: %v", err)
+	}
+	if want := []string{"二甲双胍"}; !slices.Equal(keywords, want) {
+		t.Errorf("keywords = %q, want %q", keywords, want)
+	}
+}
@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/analysis"
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// 依从性报告的默认统计窗口
+const defaultAdherenceWindow = 30 * 24 * time.Hour
+
+const dateLayout = "2006-01-02"
+
+var MedicationRoutes = []string{"oral", "subcutaneous", "intravenous", "inhaled", "topical", "other"}
+
+// AddMedication 新增一条用药方案，药物名称能在图谱中找到 Drug 实体时自动关联
+func (t *Tools) AddMedication(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	drug, err := req.RequireString("drug")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	dose, err := req.RequireFloat("dose")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if dose <= 0 {
+		return mcp.NewToolResultError("dose must be positive"), nil
+	}
+	unit, err := req.RequireString("unit")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	frequency, err := req.RequireString("frequency")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateEnum("frequency", frequency, model.MedicationFrequencies); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	route := req.GetString("route", "oral")
+	if err := validateEnum("route", route, MedicationRoutes); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	startDate := today()
+	if s := req.GetString("start_date", ""); s != "" {
+		if startDate, err = parseDate("start_date", s); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	regimen := model.MedicationRegimen{
+		Drug:      drug,
+		Dose:      float32(dose),
+		Unit:      unit,
+		Frequency: frequency,
+		Route:     route,
+		StartDate: startDate,
+		Notes:     req.GetString("notes", ""),
+	}
+	if s := req.GetString("stop_date", ""); s != "" {
+		stopDate, err := parseDate("stop_date", s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if !startDate.Before(stopDate) {
+			return mcp.NewToolResultError("stop_date must be later than start_date"), nil
+		}
+		regimen.StopDate = &stopDate
+	}
+
+	// 关联失败不影响保存方案
+	entity, err := t.graph.GetEntity(ctx, drug)
+	if err != nil {
+		slog.Warn("Failed to link medication to knowledge graph", "drug", drug, "err", err)
+	} else if entity != nil && entity.Type == "Drug" {
+		regimen.EntityID = entity.ID
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	if err := t.healthData.CreateMedicationRegimen(ctx, email, &regimen); err != nil {
+		slog.Error("Failed to create medication regimen",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to save medication regimen"), nil
+	}
+
+	return mcp.NewToolResultJSON(regimen)
+}
+
+// StopMedication 设置用药方案的停药日期
+func (t *Tools) StopMedication(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := req.RequireInt("regimen_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	stopDate := today()
+	if s := req.GetString("stop_date", ""); s != "" {
+		if stopDate, err = parseDate("stop_date", s); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	regimen, err := t.getMedicationRegimen(ctx, email, uint(id))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !regimen.StartDate.Before(stopDate) {
+		return mcp.NewToolResultError("stop_date must be later than start_date"), nil
+	}
+
+	found, err := t.healthData.StopMedicationRegimen(ctx, email, regimen.ID, stopDate)
+	if err != nil {
+		slog.Error("Failed to stop medication regimen",
+			"email", email,
+			"regimen_id", regimen.ID,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to update medication regimen"), nil
+	}
+	// 方案可能在读取后被删除
+	if !found {
+		return mcp.NewToolResultError(fmt.Sprintf("medication regimen %d not found", regimen.ID)), nil
+	}
+
+	regimen.StopDate = &stopDate
+	return mcp.NewToolResultJSON(regimen)
+}
+
+// LogMedicationDose 记录一次服药、延迟服药或跳过
+func (t *Tools) LogMedicationDose(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id, err := req.RequireInt("regimen_id")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	status, err := req.RequireString("status")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := validateEnum("status", status, model.DoseStatuses); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	takenAt := time.Now()
+	if s := req.GetString("taken_at", ""); s != "" {
+		if takenAt, err = parseRecordTime("taken_at", s); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	regimen, err := t.getMedicationRegimen(ctx, email, uint(id))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if !regimen.ActiveAt(takenAt) {
+		return mcp.NewToolResultErrorf("regimen %d is not active at %s", regimen.ID, takenAt.Format(time.RFC3339)), nil
+	}
+
+	dose := model.MedicationDose{
+		RegimenID: regimen.ID,
+		Status:    status,
+		TakenAt:   takenAt,
+		Notes:     req.GetString("notes", ""),
+	}
+	if err := t.healthData.CreateMedicationDose(ctx, email, &dose); err != nil {
+		slog.Error("Failed to create medication dose",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to save medication dose"), nil
+	}
+
+	return mcp.NewToolResultJSON(dose)
+}
+
+// AdherenceReport 统计窗口内各用药方案的服药依从性
+func (t *Tools) AdherenceReport(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	start, end, err := parseWindow(req, defaultAdherenceWindow)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	email := ctx.Value("user_email").(string)
+
+	regimens, err := t.healthData.GetMedicationRegimens(ctx, email)
+	if err != nil {
+		slog.Error("Failed to get medication regimens",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get medication regimens"), nil
+	}
+
+	query := dao.RecordQuery{Start: start, End: end}
+	if id := req.GetInt("regimen_id", 0); id != 0 {
+		regimen, err := t.getMedicationRegimen(ctx, email, uint(id))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		regimens = []model.MedicationRegimen{*regimen}
+		query.RegimenID = regimen.ID
+	}
+
+	doses, err := t.healthData.GetMedicationDoses(ctx, email, query)
+	if err != nil {
+		slog.Error("Failed to get medication doses",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get medication doses"), nil
+	}
+
+	return mcp.NewToolResultJSON(analysis.ComputeAdherence(regimens, doses, start, end))
+}
+
+// 返回当前用户指定 ID 的用药方案，不存在时返回可直接展示给调用方的错误
+func (t *Tools) getMedicationRegimen(ctx context.Context, email string, id uint) (*model.MedicationRegimen, error) {
+	regimens, err := t.healthData.GetMedicationRegimens(ctx, email)
+	if err != nil {
+		slog.Error("Failed to get medication regimens",
+			"email", email,
+			"err", err,
+		)
+		return nil, fmt.Errorf("failed to get medication regimens")
+	}
+	for _, r := range regimens {
+		if r.ID == id {
+			return &r, nil
+		}
+	}
+	return nil, fmt.Errorf("medication regimen %d not found", id)
+}
+
+// 解析本地时区的日期
+func parseDate(field, s string) (time.Time, error) {
+	d, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be a date in YYYY-MM-DD format", field)
+	}
+	return d, nil
+}
+
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
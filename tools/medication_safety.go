@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"diabetes-care-mcp-server/analysis"
+	"diabetes-care-mcp-server/dao"
+	"diabetes-care-mcp-server/model"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// 展开药物直接关系时读取的关系和原文句子上限
+	medicationRelationLimit = 200
+	medicationEvidenceLimit = 50
+	// 每条发现附带的原文句子数
+	findingEvidenceLimit = 3
+)
+
+type medicationSafetyReport struct {
+	Medications []medicationCheck `json:"medications"`
+	// Unresolved 用药列表中未能在图谱中找到药物的条目
+	Unresolved    []string `json:"unresolved"`
+	Allergies     []string `json:"allergies"`
+	Complications []string `json:"complications"`
+	// RegimensUnavailable 用药方案读取失败，报告只包含档案中的用药
+	RegimensUnavailable bool `json:"regimens_unavailable,omitempty"`
+}
+
+// medicationCheck 单个药物的检查结果，Text 为档案中提及该药物的条目
+type medicationCheck struct {
+	Text             string           `json:"text"`
+	Drug             model.EntityNode `json:"drug"`
+	AdverseEffects   []safetyFinding  `json:"adverse_effects"`
+	DiseaseRelations []safetyFinding  `json:"disease_relations"`
+	// AllergyMatches 提及该药物的过敏条目
+	AllergyMatches []string `json:"allergy_matches"`
+}
+
+// safetyFinding 药物的一条图谱关系，Matched* 为与关系另一端实体重叠的档案条目
+type safetyFinding struct {
+	Relation             string                   `json:"relation"`
+	Entity               model.EntityNode         `json:"entity"`
+	Weight               int                      `json:"weight,omitempty"`
+	Sources              []string                 `json:"sources,omitempty"`
+	MatchedComplications []string                 `json:"matched_complications,omitempty"`
+	MatchedAllergies     []string                 `json:"matched_allergies,omitempty"`
+	Evidence             []model.EvidenceSentence `json:"evidence"`
+}
+
+// CheckMedicationSafety 对照健康档案中的用药、过敏和并发症，检查图谱中药物的不良反应和疾病关系；
+// 正在使用的用药方案中的药物与档案中的用药一并检查
+func (t *Tools) CheckMedicationSafety(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	email := ctx.Value("user_email").(string)
+
+	// 用药方案读取失败时只检查档案中的用药，并在报告中标明
+	regimens, err := t.healthData.GetMedicationRegimens(ctx, email)
+	regimensUnavailable := err != nil
+	if err != nil {
+		slog.Warn("Failed to get medication regimens, checking profile medications only",
+			"email", email,
+			"err", err,
+		)
+	}
+
+	profile, err := t.healthData.GetHealthProfile(ctx, email)
+	if err != nil {
+		slog.Error("Failed to get health profile",
+			"email", email,
+			"err", err,
+		)
+		return mcp.NewToolResultError("failed to get health profile"), nil
+	}
+	if profile == nil {
+		if len(regimens) == 0 {
+			return mcp.NewToolResultError("health profile not found"), nil
+		}
+		profile = &model.HealthProfile{}
+	}
+
+	dict, err := t.entityDictionary(ctx)
+	if err != nil {
+		slog.Error("Failed to load entity dictionary", "err", err)
+		return mcp.NewToolResultError("failed to check medication safety"), nil
+	}
+
+	var medications []analysis.ProfileItem
+	now := time.Now()
+	for _, r := range regimens {
+		if r.ActiveAt(now) {
+			medications = append(medications, dict.ParseProfileList(r.Drug)...)
+		}
+	}
+	medications = append(medications, dict.ParseProfileList(profile.Medication)...)
+	allergies := dict.ParseProfileList(profile.Allergies)
+	complications := dict.ParseProfileList(profile.Complications)
+
+	report := medicationSafetyReport{
+		Medications:         []medicationCheck{},
+		Unresolved:          []string{},
+		Allergies:           itemTexts(allergies),
+		Complications:       itemTexts(complications),
+		RegimensUnavailable: regimensUnavailable,
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range medications {
+		drugs := item.EntitiesOfType("Drug")
+		if len(drugs) == 0 {
+			report.Unresolved = append(report.Unresolved, item.Text)
+			continue
+		}
+		for _, drug := range drugs {
+			if seen[drug.ID] {
+				continue
+			}
+			seen[drug.ID] = true
+
+			check, err := t.checkDrug(ctx, item.Text, drug, allergies, complications)
+			if err != nil {
+				slog.Error("Failed to check medication",
+					"drug", drug.ID,
+					"err", err,
+				)
+				return mcp.NewToolResultError("failed to check medication safety"), nil
+			}
+			report.Medications = append(report.Medications, check)
+		}
+	}
+
+	return mcp.NewToolResultJSON(report)
+}
+
+// checkDrug 展开药物的直接关系：列出全部不良反应，保留与并发症重叠的疾病关系，并匹配过敏条目
+func (t *Tools) checkDrug(ctx context.Context, text string, drug model.EntityNode, allergies, complications []analysis.ProfileItem) (medicationCheck, error) {
+	check := medicationCheck{
+		Text:             text,
+		Drug:             drug,
+		AdverseEffects:   []safetyFinding{},
+		DiseaseRelations: []safetyFinding{},
+		AllergyMatches:   matchingItems(allergies, drug),
+	}
+	if check.AllergyMatches == nil {
+		check.AllergyMatches = []string{}
+	}
+
+	details, err := t.graph.GetEntityDetails(ctx, drug.ID, dao.EntityDetailsOptions{
+		Hops:          1,
+		RelationLimit: medicationRelationLimit,
+		EvidenceLimit: medicationEvidenceLimit,
+	})
+	if err != nil || details == nil {
+		return check, err
+	}
+
+	for _, rel := range details.Relations {
+		other := rel.Head
+		if other.ID == drug.ID {
+			other = rel.Tail
+		}
+		finding := safetyFinding{
+			Relation:             rel.Type,
+			Entity:               other,
+			Weight:               rel.Weight,
+			Sources:              rel.Sources,
+			MatchedComplications: matchingItems(complications, other),
+		}
+
+		switch other.Type {
+		case "ADE":
+			finding.MatchedAllergies = matchingItems(allergies, other)
+			finding.Evidence = relationEvidence(details.Sentences, other)
+			check.AdverseEffects = append(check.AdverseEffects, finding)
+		case "Disease":
+			if len(finding.MatchedComplications) == 0 {
+				continue
+			}
+			finding.Evidence = relationEvidence(details.Sentences, other)
+			check.DiseaseRelations = append(check.DiseaseRelations, finding)
+		}
+	}
+
+	return check, nil
+}
+
+// relationEvidence 从药物的出处句子中选出同时提及关系另一端实体的句子
+func relationEvidence(sentences []model.EvidenceSentence, other model.EntityNode) []model.EvidenceSentence {
+	name := model.NormalizeEntityName(other.Name)
+	evidence := []model.EvidenceSentence{}
+	for _, s := range sentences {
+		if len(evidence) >= findingEvidenceLimit {
+			break
+		}
+		if strings.Contains(model.NormalizeEntityName(s.Text), name) {
+			evidence = append(evidence, s)
+		}
+	}
+	return evidence
+}
+
+// matchingItems 返回提及实体的档案条目
+func matchingItems(items []analysis.ProfileItem, e model.EntityNode) []string {
+	var matched []string
+	for _, item := range items {
+		if item.Mentions(e) {
+			matched = append(matched, item.Text)
+		}
+	}
+	return matched
+}
+
+func itemTexts(items []analysis.ProfileItem) []string {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.Text
+	}
+	return texts
+}
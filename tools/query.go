@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"diabetes-care-mcp-server/dao"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const maxRecordsLimit = 100
+
+// 相对时间，如 30m、12h、7d、2w，表示当前时间之前的时长
+var relativeTimePattern = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+// RecordPage 分页返回的记录，NextCursor 为空表示没有更多数据
+type RecordPage[T any] struct {
+	Records []T `json:"records"`
+	// Unit 记录中血糖值的单位，记录不含血糖值时为空
+	Unit       string `json:"unit,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// 解析请求中的时间区间、游标和条数限制
+func parseRecordQuery(req mcp.CallToolRequest) (dao.RecordQuery, error) {
+	var query dao.RecordQuery
+	var err error
+
+	now := time.Now()
+	if query.Start, err = parseTimeParam(req.GetString("start", ""), now); err != nil {
+		return query, fmt.Errorf("invalid start: %w", err)
+	}
+	if query.End, err = parseTimeParam(req.GetString("end", ""), now); err != nil {
+		return query, fmt.Errorf("invalid end: %w", err)
+	}
+	if !query.Start.IsZero() && !query.End.IsZero() && !query.Start.Before(query.End) {
+		return query, fmt.Errorf("start must be earlier than end")
+	}
+
+	if cursor := req.GetString("cursor", ""); cursor != "" {
+		if query.After, err = decodeCursor(cursor); err != nil {
+			return query, err
+		}
+	}
+
+	query.Limit = req.GetInt("limit", defaultRecordsLimit)
+	if query.Limit <= 0 || query.Limit > maxRecordsLimit {
+		return query, fmt.Errorf("limit must be between 1 and %d", maxRecordsLimit)
+	}
+
+	return query, nil
+}
+
+// parseTimeParam 解析 RFC3339 时间或相对时间，空字符串返回零值
+func parseTimeParam(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if m := relativeTimePattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := map[string]time.Duration{
+			"m": time.Minute,
+			"h": time.Hour,
+			"d": 24 * time.Hour,
+			"w": 7 * 24 * time.Hour,
+		}[m[2]]
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC3339 nor a relative time like 7d", s)
+	}
+	return t, nil
+}
+
+// 查询时多取一条判断是否存在下一页，并生成下一页游标
+func newRecordPage[T any](records []T, limit int, unit string, position func(T) dao.Cursor) RecordPage[T] {
+	page := RecordPage[T]{Records: records, Unit: unit}
+	if records == nil {
+		page.Records = []T{}
+	}
+	if len(records) > limit {
+		page.Records = records[:limit]
+		page.NextCursor = encodeCursor(position(records[limit-1]))
+	}
+	return page
+}
+
+func encodeCursor(c dao.Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*dao.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var c dao.Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &c, nil
+}
@@ -0,0 +1,17 @@
+package tools
+
+import "diabetes-care-mcp-server/dao"
+
+// Tools 持有工具处理函数所需的依赖，各 MCP 工具均实现为其方法
+type Tools struct {
+	healthData dao.HealthDataStore
+	graph      dao.KnowledgeGraph
+	dictionary entityDictionary
+}
+
+func NewTools(healthData dao.HealthDataStore, graph dao.KnowledgeGraph) *Tools {
+	return &Tools{
+		healthData: healthData,
+		graph:      graph,
+	}
+}